@@ -0,0 +1,184 @@
+package download
+
+import (
+"context"
+"fmt"
+"io"
+"net/http"
+"os"
+"path/filepath"
+"rsshub/internal/adapters/httpclient"
+"rsshub/internal/domain"
+"strconv"
+"strings"
+)
+
+// partSuffix добавляется к имени файла, пока загрузка не завершена
+const partSuffix = ".part"
+
+// Fetcher реализует интерфейс domain.EnclosureFetcher
+type Fetcher struct {
+client *http.Client
+}
+
+// NewFetcher создает загрузчик файлов. Таймаут всего запроса обычно
+// отключают (RequestTimeout = 0): загрузка видео может идти часами.
+func NewFetcher(cfg httpclient.Config) *Fetcher {
+return &Fetcher{client: httpclient.New(cfg)}
+}
+
+// Fetch загружает файл по адресу url в path. Незавершенная загрузка хранится
+// в файле path + ".part" и продолжается запросом Range, если сервер его поддерживает.
+func (f *Fetcher) Fetch(ctx context.Context, url, path string, maxSize int64, progress func(downloaded, size int64)) error {
+if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+return fmt.Errorf("ошибка создания каталога: %w", err)
+}
+
+partPath := path + partSuffix
+
+var offset int64
+if info, err := os.Stat(partPath); err == nil {
+offset = info.Size()
+}
+
+req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+if err != nil {
+return err
+}
+if offset > 0 {
+req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+}
+
+resp, err := f.client.Do(req)
+if err != nil {
+return err
+}
+defer resp.Body.Close()
+
+flags := os.O_CREATE | os.O_WRONLY
+var size int64
+
+switch {
+case resp.StatusCode == http.StatusPartialContent && offset > 0:
+start, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+if !ok || start != offset {
+return fmt.Errorf("сервер вернул неожиданный диапазон: %q", resp.Header.Get("Content-Range"))
+}
+size = total
+flags |= os.O_APPEND
+
+case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+// Файл уже загружен полностью, если его размер совпадает с размером на сервере
+if _, total, ok := parseContentRange(resp.Header.Get("Content-Range")); ok && total == offset {
+if progress != nil {
+progress(offset, offset)
+}
+return os.Rename(partPath, path)
+}
+// Файл на сервере изменился: загружаем заново при следующей попытке
+os.Remove(partPath)
+return fmt.Errorf("файл на сервере изменился, загрузка начнется заново")
+
+case resp.StatusCode >= 200 && resp.StatusCode < 300:
+// Сервер не поддерживает Range: загружаем файл с начала
+offset = 0
+flags |= os.O_TRUNC
+if resp.ContentLength > 0 {
+size = resp.ContentLength
+}
+
+default:
+return fmt.Errorf("неожиданный код ответа: %d", resp.StatusCode)
+}
+
+if maxSize > 0 && size > maxSize {
+os.Remove(partPath)
+return fmt.Errorf("%w: %d байт", domain.ErrDownloadTooLarge, size)
+}
+
+file, err := os.OpenFile(partPath, flags, 0o644)
+if err != nil {
+return fmt.Errorf("ошибка открытия файла: %w", err)
+}
+
+body := io.Reader(resp.Body)
+if maxSize > 0 {
+// Читаем на байт больше лимита, чтобы обнаружить его превышение
+body = io.LimitReader(resp.Body, maxSize-offset+1)
+}
+
+writer := &progressWriter{file: file, downloaded: offset, size: size, progress: progress}
+_, copyErr := io.Copy(writer, body)
+closeErr := file.Close()
+
+if copyErr != nil {
+return copyErr
+}
+if closeErr != nil {
+return closeErr
+}
+
+if maxSize > 0 && writer.downloaded > maxSize {
+os.Remove(partPath)
+return fmt.Errorf("%w: более %d байт", domain.ErrDownloadTooLarge, maxSize)
+}
+if size > 0 && writer.downloaded < size {
+return fmt.Errorf("загрузка прервана: получено %d из %d байт", writer.downloaded, size)
+}
+
+return os.Rename(partPath, path)
+}
+
+// progressWriter записывает данные в файл и сообщает о ходе загрузки
+type progressWriter struct {
+file       *os.File
+downloaded int64
+size       int64
+progress   func(downloaded, size int64)
+}
+
+// Write записывает данные в файл
+func (w *progressWriter) Write(p []byte) (int, error) {
+n, err := w.file.Write(p)
+w.downloaded += int64(n)
+if w.progress != nil {
+w.progress(w.downloaded, w.size)
+}
+return n, err
+}
+
+// parseContentRange разбирает заголовок Content-Range вида "bytes 100-199/200"
+// или "bytes */200". Полный размер равен 0, если сервер его не сообщил ("/*").
+func parseContentRange(header string) (start, total int64, ok bool) {
+spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes ")
+if !found {
+return 0, 0, false
+}
+
+rangePart, totalPart, found := strings.Cut(spec, "/")
+if !found {
+return 0, 0, false
+}
+
+if totalPart != "*" {
+value, err := strconv.ParseInt(totalPart, 10, 64)
+if err != nil {
+return 0, 0, false
+}
+total = value
+}
+
+if rangePart == "*" {
+return 0, total, true
+}
+
+startPart, _, found := strings.Cut(rangePart, "-")
+if !found {
+return 0, 0, false
+}
+start, err := strconv.ParseInt(startPart, 10, 64)
+if err != nil {
+return 0, 0, false
+}
+return start, total, true
+}
@@ -0,0 +1,172 @@
+package httpclient
+
+import (
+"context"
+"errors"
+"fmt"
+"net"
+"net/http"
+"net/netip"
+neturl "net/url"
+"strings"
+"syscall"
+)
+
+// ErrBlockedAddress возвращается при попытке соединения с адресом внутренней
+// сети: loopback, link-local (включая 169.254.169.254), частные и служебные диапазоны
+var ErrBlockedAddress = errors.New("запрос к адресу внутренней сети запрещен")
+
+// blockedPrefixes дополняет проверки netip служебными диапазонами
+var blockedPrefixes = []netip.Prefix{
+netip.MustParsePrefix("0.0.0.0/8"),     // "этот" сетевой адрес
+netip.MustParsePrefix("100.64.0.0/10"), // CGNAT
+netip.MustParsePrefix("192.0.0.0/24"),  // служебные адреса IETF
+netip.MustParsePrefix("198.18.0.0/15"), // тестирование производительности сетей
+netip.MustParsePrefix("240.0.0.0/4"),   // зарезервированные и широковещательный
+netip.MustParsePrefix("64:ff9b::/96"),  // NAT64 может вести во внутреннюю IPv4-сеть
+netip.MustParsePrefix("2001:db8::/32"), // документация
+}
+
+// addressGuard запрещает соединения с адресами внутренних сетей, кроме
+// разрешенных хостов и сетей. Адрес проверяется после разрешения DNS при
+// каждом соединении, поэтому проверка действует и при перенаправлениях,
+// и при подмене DNS-записи после проверки имени (DNS rebinding).
+type addressGuard struct {
+hosts    map[string]bool
+suffixes []string
+networks []netip.Prefix
+}
+
+// allowedHostKey отмечает в контексте соединения хост из списка разрешенных
+type allowedHostKey struct{}
+
+// newAddressGuard разбирает список разрешенных хостов и сетей. Элемент списка —
+// имя хоста ("feeds.intranet"), домен с точкой в начале (".intranet" — все
+// поддомены), IP-адрес или сеть в нотации CIDR ("10.0.0.0/8").
+func newAddressGuard(allowlist []string) *addressGuard {
+guard := &addressGuard{hosts: make(map[string]bool)}
+for _, entry := range allowlist {
+entry = strings.ToLower(strings.TrimSpace(entry))
+switch {
+case entry == "":
+case strings.Contains(entry, "/"):
+if prefix, err := netip.ParsePrefix(entry); err == nil {
+guard.networks = append(guard.networks, prefix.Masked())
+}
+case strings.HasPrefix(entry, "."):
+guard.suffixes = append(guard.suffixes, entry)
+default:
+if addr, err := netip.ParseAddr(entry); err == nil {
+guard.networks = append(guard.networks, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+continue
+}
+guard.hosts[entry] = true
+}
+}
+return guard
+}
+
+// hostAllowed проверяет, входит ли имя хоста в список разрешенных
+func (g *addressGuard) hostAllowed(host string) bool {
+host = strings.TrimSuffix(strings.ToLower(host), ".")
+if g.hosts[host] {
+return true
+}
+for _, suffix := range g.suffixes {
+if strings.HasSuffix(host, suffix) {
+return true
+}
+}
+return false
+}
+
+// dialContext отмечает соединения с разрешенными хостами перед установкой соединения
+func (g *addressGuard) dialContext(dial func(ctx context.Context, network, address string) (net.Conn, error)) func(ctx context.Context, network, address string) (net.Conn, error) {
+return func(ctx context.Context, network, address string) (net.Conn, error) {
+if host, _, err := net.SplitHostPort(address); err == nil && g.hostAllowed(host) {
+ctx = context.WithValue(ctx, allowedHostKey{}, true)
+}
+return dial(ctx, network, address)
+}
+}
+
+// proxy проверяет адрес запроса, который будет передан прокси-серверу.
+// Соединение устанавливается с прокси, поэтому control проверяет только его
+// адрес; адрес запроса проверяется здесь после разрешения DNS. Прокси
+// разрешает имя повторно, поэтому подмена DNS-записи между проверкой
+// и запросом через прокси не обнаруживается.
+func (g *addressGuard) proxy(next func(*http.Request) (*neturl.URL, error)) func(*http.Request) (*neturl.URL, error) {
+return func(req *http.Request) (*neturl.URL, error) {
+proxyURL, err := next(req)
+if err != nil || proxyURL == nil {
+return proxyURL, err
+}
+if err := g.checkHost(req.Context(), req.URL.Hostname()); err != nil {
+return nil, err
+}
+return proxyURL, nil
+}
+}
+
+// checkHost разрешает имя хоста и проверяет все его адреса
+func (g *addressGuard) checkHost(ctx context.Context, host string) error {
+if g.hostAllowed(host) {
+return nil
+}
+if addr, err := netip.ParseAddr(host); err == nil {
+return g.checkAddr(addr)
+}
+
+addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+if err != nil {
+return err
+}
+for _, addr := range addrs {
+if err := g.checkAddr(addr); err != nil {
+return err
+}
+}
+return nil
+}
+
+// control проверяет IP-адрес, с которым устанавливается соединение
+func (g *addressGuard) control(ctx context.Context, network, address string, _ syscall.RawConn) error {
+if allowed, _ := ctx.Value(allowedHostKey{}).(bool); allowed {
+return nil
+}
+
+addrPort, err := netip.ParseAddrPort(address)
+if err != nil {
+return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+}
+return g.checkAddr(addrPort.Addr())
+}
+
+// checkAddr запрещает адреса внутренних сетей, кроме разрешенных сетей
+func (g *addressGuard) checkAddr(addr netip.Addr) error {
+addr = addr.Unmap()
+for _, prefix := range g.networks {
+if prefix.Contains(addr) {
+return nil
+}
+}
+if isInternalAddr(addr) {
+return fmt.Errorf("%w: %s", ErrBlockedAddress, addr)
+}
+return nil
+}
+
+// isInternalAddr проверяет, относится ли адрес к внутренней или служебной сети
+func isInternalAddr(addr netip.Addr) bool {
+if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+return true
+}
+for _, prefix := range blockedPrefixes {
+if prefix.Contains(addr) {
+return true
+}
+}
+return false
+}
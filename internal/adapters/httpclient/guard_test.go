@@ -0,0 +1,172 @@
+package httpclient
+
+import (
+"context"
+"errors"
+"net/http"
+"net/http/httptest"
+"net/netip"
+neturl "net/url"
+"strings"
+"testing"
+"time"
+)
+
+func testConfig(allowlist ...string) Config {
+cfg := DefaultConfig()
+cfg.RequestTimeout = 5 * time.Second
+cfg.Allowlist = allowlist
+return cfg
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+t.Helper()
+server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+w.Write([]byte("ok"))
+}))
+t.Cleanup(server.Close)
+return server
+}
+
+func TestGuardBlocksLoopback(t *testing.T) {
+server := newTestServer(t)
+
+_, err := New(testConfig()).Get(server.URL)
+if !errors.Is(err, ErrBlockedAddress) {
+t.Fatalf("ожидалась ошибка ErrBlockedAddress, получено %v", err)
+}
+
+cfg := testConfig()
+cfg.AllowInternal = true
+resp, err := New(cfg).Get(server.URL)
+if err != nil {
+t.Fatalf("AllowInternal: неожиданная ошибка: %v", err)
+}
+resp.Body.Close()
+}
+
+func TestGuardBlocksRedirectToMetadata(t *testing.T) {
+server := httptest.NewServer(http.RedirectHandler("http://169.254.169.254/latest/meta-data/", http.StatusFound))
+defer server.Close()
+
+// Сам тестовый сервер разрешен, адрес перенаправления — нет
+_, err := New(testConfig("127.0.0.1")).Get(server.URL)
+if !errors.Is(err, ErrBlockedAddress) {
+t.Fatalf("ожидалась ошибка ErrBlockedAddress, получено %v", err)
+}
+if !strings.Contains(err.Error(), "169.254.169.254") {
+t.Fatalf("ошибка не содержит адрес перенаправления: %v", err)
+}
+}
+
+func TestGuardBlocksResolvedHostname(t *testing.T) {
+server := newTestServer(t)
+port := server.URL[strings.LastIndexByte(server.URL, ':')+1:]
+
+// Имя localhost разрешается в адрес loopback
+_, err := New(testConfig()).Get("http://localhost:" + port)
+if !errors.Is(err, ErrBlockedAddress) {
+t.Fatalf("ожидалась ошибка ErrBlockedAddress, получено %v", err)
+}
+
+// Разрешенное имя не проверяется по адресу, но адрес сервера
+// без имени по-прежнему запрещен
+resp, err := New(testConfig("localhost")).Get("http://localhost:" + port)
+if err != nil {
+t.Fatalf("разрешенный хост: неожиданная ошибка: %v", err)
+}
+resp.Body.Close()
+_, err = New(testConfig("localhost")).Get(server.URL)
+if !errors.Is(err, ErrBlockedAddress) {
+t.Fatalf("ожидалась ошибка ErrBlockedAddress, получено %v", err)
+}
+}
+
+func TestGuardAllowlist(t *testing.T) {
+guard := newAddressGuard([]string{"10.1.0.0/16", "192.168.1.5", ".intranet", "Feeds.Example", " ", "bad/cidr"})
+
+hosts := map[string]bool{
+"feeds.example":      true,
+"FEEDS.EXAMPLE.":     true,
+"news.intranet":      true,
+"a.b.intranet":       true,
+"intranet":           false,
+"evilintranet":       false,
+"other.example":      false,
+"feeds.example.evil": false,
+}
+for host, want := range hosts {
+if got := guard.hostAllowed(host); got != want {
+t.Errorf("hostAllowed(%q) = %v, ожидалось %v", host, got, want)
+}
+}
+
+addrs := map[string]bool{
+"10.1.2.3:80":             true,
+"10.2.0.1:80":             false,
+"192.168.1.5:443":         true,
+"192.168.1.6:443":         false,
+"[::ffff:10.1.0.1]:80":    true,
+"127.0.0.1:80":            false,
+"169.254.169.254:80":      false,
+"[::1]:80":                false,
+"[fd00::1]:80":            false,
+"[fe80::1]:80":            false,
+"100.64.0.1:80":           false,
+"0.0.0.0:80":              false,
+"93.184.216.34:443":       true,
+"[2606:4700::6810:1]:443": true,
+}
+for address, want := range addrs {
+err := guard.control(context.Background(), "tcp", address, nil)
+if got := err == nil; got != want {
+t.Errorf("control(%q): ошибка %v, ожидалось разрешение %v", address, err, want)
+}
+}
+}
+
+func TestGuardProxyChecksTarget(t *testing.T) {
+proxyURL, err := neturl.Parse("http://proxy.intranet:3128")
+if err != nil {
+t.Fatal(err)
+}
+// Прокси во внутренней сети разрешен списком, адреса запросов — нет
+guard := newAddressGuard([]string{".intranet"})
+proxy := guard.proxy(func(*http.Request) (*neturl.URL, error) { return proxyURL, nil })
+
+tests := map[string]bool{
+"http://127.0.0.1/":             false,
+"http://169.254.169.254/":       false,
+"http://localhost/":             false,
+"http://[::1]/":                 false,
+"http://feeds.intranet/rss":     true,
+"http://93.184.216.34/feed.xml": true,
+}
+for target, want := range tests {
+req, err := http.NewRequest(http.MethodGet, target, nil)
+if err != nil {
+t.Fatal(err)
+}
+got, err := proxy(req)
+if want && (err != nil || got != proxyURL) {
+t.Errorf("%s: ожидался прокси, получено %v, %v", target, got, err)
+}
+if !want && !errors.Is(err, ErrBlockedAddress) {
+t.Errorf("%s: ожидалась ошибка ErrBlockedAddress, получено %v", target, err)
+}
+}
+}
+
+func TestIsInternalAddr(t *testing.T) {
+for _, addr := range []string{"10.0.0.1", "172.16.0.1", "192.168.0.1", "127.0.0.1", "169.254.1.1",
+"224.0.0.1", "198.18.0.1", "240.0.0.1", "::1", "fc00::1", "64:ff9b::a00:1"} {
+if !isInternalAddr(netip.MustParseAddr(addr)) {
+t.Errorf("адрес %s должен считаться внутренним", addr)
+}
+}
+for _, addr := range []string{"8.8.8.8", "93.184.216.34", "2001:4860:4860::8888"} {
+if isInternalAddr(netip.MustParseAddr(addr)) {
+t.Errorf("адрес %s не должен считаться внутренним", addr)
+}
+}
+}
@@ -0,0 +1,260 @@
+package markup
+
+import (
+"html"
+neturl "net/url"
+"strconv"
+"strings"
+)
+
+// allowedElements перечисляет разрешенные элементы и их атрибуты.
+// Остальные элементы удаляются, а их текст сохраняется.
+var allowedElements = map[string][]string{
+"a": {"href"}, "abbr": nil, "b": nil, "blockquote": {"cite"}, "br": nil,
+"caption": nil, "cite": nil, "code": nil, "dd": nil, "del": nil, "details": nil,
+"dfn": nil, "div": nil, "dl": nil, "dt": nil, "em": nil, "figcaption": nil, "figure": nil,
+"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil, "hr": nil,
+"i": nil, "img": {"src", "alt", "width", "height"}, "ins": nil, "kbd": nil, "li": nil,
+"mark": nil, "ol": {"start", "type"}, "p": nil, "pre": nil, "q": {"cite"}, "s": nil,
+"samp": nil, "small": nil, "span": nil, "strike": nil, "strong": nil, "sub": nil,
+"summary": nil, "sup": nil, "table": nil, "tbody": nil, "td": {"colspan", "rowspan"},
+"tfoot": nil, "th": {"colspan", "rowspan", "scope"}, "thead": nil, "time": {"datetime"},
+"tr": nil, "u": nil, "ul": nil, "var": nil, "wbr": nil,
+"audio": {"src", "controls"}, "video": {"src", "poster", "controls", "width", "height"},
+"source": {"src", "type"}, "picture": nil,
+}
+
+// globalAttributes разрешены для любого элемента
+var globalAttributes = []string{"title", "lang", "dir"}
+
+// droppedElements удаляются вместе с содержимым
+var droppedElements = map[string]bool{
+"script": true, "style": true, "iframe": true, "frame": true, "frameset": true,
+"object": true, "embed": true, "applet": true, "noscript": true, "noembed": true,
+"noframes": true, "template": true, "svg": true, "math": true, "form": true,
+"textarea": true, "select": true, "button": true, "head": true, "title": true, "xmp": true,
+}
+
+// voidElements не имеют содержимого и закрывающего тега
+var voidElements = map[string]bool{
+"br": true, "hr": true, "img": true, "source": true, "wbr": true,
+}
+
+// impliedEnds перечисляет элементы, которые закрываются открывающим тегом
+// элемента-ключа, если он записан сразу в них: <li>один<li>два
+var impliedEnds = map[string][]string{
+"li": {"li"}, "p": {"p"}, "dt": {"dt", "dd"}, "dd": {"dt", "dd"},
+"tr": {"tr", "td", "th"}, "td": {"td", "th"}, "th": {"td", "th"},
+}
+
+// urlAttributes содержат адреса, которые проверяются на безопасность схемы
+var urlAttributes = map[string]bool{
+"href": true, "src": true, "cite": true, "poster": true,
+}
+
+// safeSchemes перечисляет разрешенные схемы адресов
+var safeSchemes = map[string]bool{
+"http": true, "https": true, "mailto": true,
+}
+
+// Sanitize оставляет в HTML-фрагменте безопасное подмножество разметки:
+// удаляет скрипты, фреймы, формы, стили, обработчики событий, адреса
+// с опасными схемами (javascript:, data:) и счетчики-пиксели. Теги
+// закрываются в правильном порядке, незакрытые — в конце фрагмента.
+// Относительные адреса ссылок и изображений разрешаются относительно base,
+// если он указан.
+func Sanitize(fragment string, base *neturl.URL) string {
+var (
+out   strings.Builder
+open  []string
+skip  string
+depth int
+)
+
+tokenizer := NewTokenizer(fragment)
+for {
+token, ok := tokenizer.Next()
+if !ok {
+break
+}
+
+// Содержимое удаляемого элемента пропускается до его закрывающего тега
+if skip != "" {
+switch {
+case token.Type == StartTagToken && token.Data == skip:
+depth++
+case token.Type == EndTagToken && token.Data == skip:
+depth--
+if depth == 0 {
+skip = ""
+}
+}
+continue
+}
+
+switch token.Type {
+case TextToken:
+out.WriteString(html.EscapeString(token.Data))
+
+case StartTagToken, SelfClosingTagToken:
+if droppedElements[token.Data] {
+if token.Type == StartTagToken {
+skip, depth = token.Data, 1
+}
+continue
+}
+if _, ok := allowedElements[token.Data]; !ok || isTrackingPixel(token) {
+continue
+}
+
+// Незакрытый элемент списка, абзац или ячейка закрываются следующим таким же элементом
+for len(open) > 0 && containsString(impliedEnds[token.Data], open[len(open)-1]) {
+out.WriteString("</" + open[len(open)-1] + ">")
+open = open[:len(open)-1]
+}
+
+writeStartTag(&out, token, base)
+switch {
+case voidElements[token.Data]:
+case token.Type == SelfClosingTagToken:
+out.WriteString("</" + token.Data + ">")
+default:
+open = append(open, token.Data)
+}
+
+case EndTagToken:
+// Закрывающий тег без открывающего пропускается; пропущенные
+// закрывающие теги вложенных элементов добавляются
+for i := len(open) - 1; i >= 0; i-- {
+if open[i] != token.Data {
+continue
+}
+for j := len(open) - 1; j >= i; j-- {
+out.WriteString("</" + open[j] + ">")
+}
+open = open[:i]
+break
+}
+}
+}
+
+for i := len(open) - 1; i >= 0; i-- {
+out.WriteString("</" + open[i] + ">")
+}
+return out.String()
+}
+
+// writeStartTag записывает открывающий тег с разрешенными атрибутами
+func writeStartTag(out *strings.Builder, token Token, base *neturl.URL) {
+out.WriteString("<" + token.Data)
+
+allowed := allowedElements[token.Data]
+for _, attr := range token.Attrs {
+if !containsString(allowed, attr.Name) && !containsString(globalAttributes, attr.Name) {
+continue
+}
+value := attr.Value
+if urlAttributes[attr.Name] {
+var ok bool
+if value, ok = safeURL(value); !ok {
+continue
+}
+value = ResolveURL(base, value)
+}
+out.WriteString(" " + attr.Name + `="` + html.EscapeString(value) + `"`)
+}
+
+// Внешние ссылки не передают сайту доступ к окну читателя и не влияют на поиск
+if token.Data == "a" {
+out.WriteString(` rel="nofollow noopener noreferrer"`)
+}
+
+if voidElements[token.Data] {
+out.WriteString(" />")
+} else {
+out.WriteString(">")
+}
+}
+
+// safeURL проверяет схему адреса. Относительные адреса разрешены.
+func safeURL(value string) (string, bool) {
+// Браузеры игнорируют управляющие символы и пробелы внутри схемы ("java\tscript:")
+cleaned := strings.Map(func(r rune) rune {
+if r <= ' ' || r == 0x7f {
+return -1
+}
+return r
+}, value)
+if cleaned == "" {
+return "", false
+}
+
+colon := strings.IndexByte(cleaned, ':')
+if colon < 0 || strings.ContainsAny(cleaned[:colon], "/?#") {
+return strings.TrimSpace(value), true
+}
+if !safeSchemes[strings.ToLower(cleaned[:colon])] {
+return "", false
+}
+return strings.TrimSpace(value), true
+}
+
+// ResolveURL разрешает адрес ref относительно base. Абсолютные адреса,
+// некорректные адреса и адреса при пустом base возвращаются без изменений.
+func ResolveURL(base *neturl.URL, ref string) string {
+ref = strings.TrimSpace(ref)
+if base == nil || ref == "" {
+return ref
+}
+parsed, err := neturl.Parse(ref)
+if err != nil || parsed.IsAbs() {
+return ref
+}
+return base.ResolveReference(parsed).String()
+}
+
+// trackingHosts содержит фрагменты адресов распространенных счетчиков в каналах
+var trackingHosts = []string{
+"feeds.feedburner.com/~r/", "feeds.feedburner.com/~ff/", "feedproxy.google.com/~r/",
+"stats.wordpress.com/", "pixel.wp.com/", "rss.feedsportal.com/c/",
+"pixel.quantserve.com/", "www.google-analytics.com/", "mc.yandex.ru/watch",
+"counter.yadro.ru/", "top-fwz1.mail.ru/counter", "medium.com/_/stat",
+}
+
+// isTrackingPixel определяет невидимые изображения-счетчики: изображения
+// размером не больше 1×1 и изображения с адресов известных счетчиков
+func isTrackingPixel(token Token) bool {
+if token.Data != "img" {
+return false
+}
+
+width, hasWidth := token.Attr("width")
+height, hasHeight := token.Attr("height")
+if hasWidth && hasHeight && isTinySize(width) && isTinySize(height) {
+return true
+}
+
+src, _ := token.Attr("src")
+src = strings.ToLower(src)
+for _, host := range trackingHosts {
+if strings.Contains(src, host) {
+return true
+}
+}
+return false
+}
+
+// isTinySize проверяет, что размер изображения не больше одного пикселя
+func isTinySize(value string) bool {
+size, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "px"))
+return err == nil && size <= 1
+}
+
+func containsString(values []string, value string) bool {
+for _, v := range values {
+if v == value {
+return true
+}
+}
+return false
+}
@@ -0,0 +1,90 @@
+package markup
+
+import (
+neturl "net/url"
+"testing"
+)
+
+const rel = ` rel="nofollow noopener noreferrer"`
+
+func TestSanitize(t *testing.T) {
+base, err := neturl.Parse("https://example.com/blog/post")
+if err != nil {
+t.Fatal(err)
+}
+
+tests := []struct {
+name string
+in   string
+want string
+}{
+// Опасные схемы адресов
+{"javascript", `<a href="javascript:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+{"javascript в другом регистре", `<a href="JaVaScRiPt:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+{"пробел и табуляция в схеме", `<a href=" java&#x09;script:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+{"именованная сущность в схеме", `<a href="java&Tab;script:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+{"числовая сущность в схеме", `<a href="jav&#97;script:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+{"управляющий символ перед схемой", "<a href=\"\x01javascript:alert(1)\">x</a>", `<a` + rel + `>x</a>`},
+{"vbscript", `<a href="vbscript:msgbox">x</a>`, `<a` + rel + `>x</a>`},
+{"data в изображении", `<img src="data:image/svg+xml;base64,AAAA">`, `<img />`},
+{"mailto разрешен", `<a href="mailto:a@example.com">m</a>`, `<a href="mailto:a@example.com"` + rel + `>m</a>`},
+
+// Обработчики событий и неразрешенные атрибуты
+{"обработчики событий", `<p onclick="alert(1)" ONMOUSEOVER=x style="color:red">hi</p>`, `<p>hi</p>`},
+{"onerror изображения", `<img src=x.png onerror=alert(1)>`, `<img src="https://example.com/blog/x.png" />`},
+{"выход из значения атрибута", `<a title='"><script>x</script>'>q</a>`,
+`<a title="&#34;&gt;&lt;script&gt;x&lt;/script&gt;"` + rel + `>q</a>`},
+
+// Удаляемые вместе с содержимым элементы
+{"script", `<script>alert(1)</script>ok`, `ok`},
+{"закрывающий тег внутри script", `<script>document.write("</p>")</script>ok`, `ok`},
+{"script в верхнем регистре", `<SCRIPT>alert(1)</SCRIPT >ok`, `ok`},
+{"незакрытый script", `ok<script>alert(1)`, `ok`},
+{"svg", `<svg><script>alert(1)</script><circle onload="x"/></svg>after`, `after`},
+// Без закрывающего тега svg удаляется все до конца фрагмента
+{"svg с обработчиком", `<svg/onload=alert(1)>after`, ``},
+{"math", `<math><mi xlink:href="javascript:x">y</mi></math>after`, `after`},
+{"style", `<style>body{}</style>text`, `text`},
+{"iframe", `<iframe src="https://evil.example"></iframe>text`, `text`},
+{"форма", `<form action="x"><input name=a>text</form>after`, `after`},
+{"комментарий", `<!-- <script>alert(1)</script> -->ok`, `ok`},
+{"текст экранируется", `a < b & c > d`, `a &lt; b &amp; c &gt; d`},
+
+// Счетчики
+{"пиксель 1×1", `<img src="http://feeds.feedburner.com/~r/x/~4/abc" height="1" width="1">`, ``},
+{"адрес счетчика", `<img src="https://stats.wordpress.com/b.gif?x=1">`, ``},
+{"размер в px", `<img src="/pixel.gif" width="1px" height="0">`, ``},
+{"узкое изображение не счетчик", `<img src="/photo.jpg" width="100" height="1">`,
+`<img src="https://example.com/photo.jpg" width="100" height="1" />`},
+
+// Незакрытые и неправильно вложенные теги
+{"незакрытые теги", `<b>bold<i>both`, `<b>bold<i>both</i></b>`},
+{"неправильная вложенность", `<b><i>x</b></i>`, `<b><i>x</i></b>`},
+{"закрывающий тег без открывающего", `text</div>`, `text`},
+{"незакрытые элементы списка", `<ul><li>one<li>two</ul>`, `<ul><li>one</li><li>two</li></ul>`},
+{"незакрытые абзацы", `<div><p>one<p>two</div>`, `<div><p>one</p><p>two</p></div>`},
+
+// Относительные адреса
+{"абсолютный путь", `<a href="/post">a</a>`, `<a href="https://example.com/post"` + rel + `>a</a>`},
+{"относительный путь", `<img src="img.png">`, `<img src="https://example.com/blog/img.png" />`},
+{"родительский каталог", `<a href="../up">u</a>`, `<a href="https://example.com/up"` + rel + `>u</a>`},
+{"фрагмент", `<a href="#top">t</a>`, `<a href="https://example.com/blog/post#top"` + rel + `>t</a>`},
+{"абсолютный адрес не меняется", `<a href="http://other.example/x">o</a>`, `<a href="http://other.example/x"` + rel + `>o</a>`},
+}
+
+for _, tt := range tests {
+t.Run(tt.name, func(t *testing.T) {
+if got := Sanitize(tt.in, base); got != tt.want {
+t.Errorf("Sanitize(%q)\nполучено:  %q\nожидалось: %q", tt.in, got, tt.want)
+}
+})
+}
+}
+
+func TestSanitizeWithoutBase(t *testing.T) {
+got := Sanitize(`<a href="/post">a</a>`, nil)
+want := `<a href="/post"` + rel + `>a</a>`
+if got != want {
+t.Errorf("получено %q, ожидалось %q", got, want)
+}
+}
@@ -0,0 +1,221 @@
+package markup
+
+import (
+"strings"
+)
+
+// blockElements начинают новую строку в текстовом представлении
+var blockElements = map[string]bool{
+"address": true, "article": true, "aside": true, "blockquote": true, "caption": true,
+"dd": true, "details": true, "div": true, "dl": true, "dt": true, "figcaption": true,
+"figure": true, "footer": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+"h6": true, "header": true, "hr": true, "li": true, "main": true, "nav": true, "ol": true,
+"p": true, "pre": true, "section": true, "summary": true, "table": true, "tr": true, "ul": true,
+}
+
+// paragraphElements отделяются от соседнего текста пустой строкой
+var paragraphElements = map[string]bool{
+"blockquote": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+"ol": true, "p": true, "pre": true, "table": true, "ul": true,
+}
+
+// PlainText преобразует HTML-фрагмент в текст для вывода в терминал:
+// удаляет разметку, раскодирует сущности, схлопывает пробелы и
+// разбивает текст на строки по блочным элементам. Элементы списка
+// отмечаются "•", ячейки таблицы разделяются табуляцией.
+func PlainText(fragment string) string {
+var (
+w     textWriter
+skip  string
+depth int
+pre   int
+)
+
+tokenizer := NewTokenizer(fragment)
+for {
+token, ok := tokenizer.Next()
+if !ok {
+break
+}
+
+if skip != "" {
+switch {
+case token.Type == StartTagToken && token.Data == skip:
+depth++
+case token.Type == EndTagToken && token.Data == skip:
+depth--
+if depth == 0 {
+skip = ""
+}
+}
+continue
+}
+
+switch token.Type {
+case TextToken:
+if pre > 0 {
+w.writePre(token.Data)
+} else {
+w.writeText(token.Data)
+}
+
+case StartTagToken, SelfClosingTagToken:
+name := token.Data
+switch {
+case droppedElements[name]:
+if token.Type == StartTagToken {
+skip, depth = name, 1
+}
+case name == "br":
+w.lineBreak()
+case name == "img":
+if alt, _ := token.Attr("alt"); strings.TrimSpace(alt) != "" && !isTrackingPixel(token) {
+w.writeText("[" + strings.TrimSpace(alt) + "]")
+}
+case name == "li":
+w.newLine()
+w.writeText("• ")
+case name == "td" || name == "th":
+w.cell()
+case paragraphElements[name]:
+w.paragraph()
+case blockElements[name]:
+w.newLine()
+}
+if name == "pre" && token.Type == StartTagToken {
+pre++
+}
+
+case EndTagToken:
+switch {
+case token.Data == "pre" && pre > 0:
+pre--
+w.paragraph()
+case paragraphElements[token.Data]:
+w.paragraph()
+case blockElements[token.Data]:
+w.newLine()
+}
+}
+}
+
+return w.String()
+}
+
+// textWriter собирает текст, схлопывая пробелы и пустые строки
+type textWriter struct {
+lines []string
+line  strings.Builder
+// space — перед следующим словом нужен пробел
+space bool
+// blank — перед следующим текстом нужна пустая строка
+blank bool
+// cells — количество ячеек в текущей строке таблицы
+cells int
+}
+
+// writeText добавляет текст, заменяя последовательности пробелов одним пробелом
+func (w *textWriter) writeText(text string) {
+if text == "" {
+return
+}
+if startsWithSpace(text) && w.line.Len() > 0 {
+w.space = true
+}
+
+words := strings.Fields(text)
+for i, word := range words {
+w.writeWord(word, i > 0)
+}
+if len(words) > 0 && endsWithSpace(text) {
+w.space = true
+}
+}
+
+// writePre добавляет текст с сохранением переносов строк (<pre>)
+func (w *textWriter) writePre(text string) {
+for i, line := range strings.Split(text, "\n") {
+if i > 0 {
+w.lineBreak()
+}
+if line = strings.TrimRight(line, " \t\r"); line != "" {
+w.startLine()
+w.line.WriteString(line)
+}
+}
+}
+
+// writeWord добавляет слово, при необходимости отделяя его пробелом
+func (w *textWriter) writeWord(word string, separated bool) {
+w.startLine()
+if (separated || w.space) && w.line.Len() > 0 {
+w.line.WriteByte(' ')
+}
+w.space = false
+w.line.WriteString(word)
+}
+
+// startLine добавляет отложенную пустую строку перед первым текстом строки
+func (w *textWriter) startLine() {
+if w.line.Len() == 0 && w.blank && len(w.lines) > 0 {
+w.lines = append(w.lines, "")
+}
+w.blank = false
+}
+
+// lineBreak завершает текущую строку, даже если она пустая (<br>)
+func (w *textWriter) lineBreak() {
+w.lines = append(w.lines, strings.TrimRight(w.line.String(), " "))
+w.line.Reset()
+w.space = false
+w.cells = 0
+}
+
+// newLine завершает текущую строку, если в ней есть текст
+func (w *textWriter) newLine() {
+if w.line.Len() > 0 {
+w.lineBreak()
+}
+w.cells = 0
+}
+
+// paragraph завершает строку и отделяет следующий текст пустой строкой
+func (w *textWriter) paragraph() {
+w.newLine()
+w.blank = true
+}
+
+// cell отделяет ячейку таблицы от предыдущей
+func (w *textWriter) cell() {
+if w.cells > 0 && w.line.Len() > 0 {
+w.line.WriteByte('\t')
+w.space = false
+}
+w.cells++
+}
+
+// String возвращает текст без пустых строк в начале и конце и с не более
+// чем одной пустой строкой подряд
+func (w *textWriter) String() string {
+lines := w.lines
+if w.line.Len() > 0 {
+lines = append(lines, strings.TrimRight(w.line.String(), " "))
+}
+
+result := make([]string, 0, len(lines))
+for _, line := range lines {
+if line == "" && (len(result) == 0 || result[len(result)-1] == "") {
+continue
+}
+result = append(result, line)
+}
+return strings.TrimSpace(strings.Join(result, "\n"))
+}
+
+func startsWithSpace(text string) bool {
+return strings.TrimLeft(text, " \t\r\n\f") != text
+}
+
+func endsWithSpace(text string) bool {
+return strings.TrimRight(text, " \t\r\n\f") != text
+}
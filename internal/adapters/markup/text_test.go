@@ -0,0 +1,26 @@
+package markup
+
+import "testing"
+
+func TestPlainText(t *testing.T) {
+tests := []struct {
+name string
+in   string
+want string
+}{
+{"абзацы", `<p>one</p><p>two &amp; three</p>`, "one\n\ntwo & three"},
+{"список", `<ul><li>a<li>b</ul>`, "• a\n• b"},
+{"перенос строки", `a<br>b`, "a\nb"},
+{"скрипт и пробелы", `<script>x</script>  spaced   text `, "spaced text"},
+{"таблица", `<table><tr><td>1<td>2</table>`, "1\t2"},
+{"числовая сущность", `It&#8217;s`, "It’s"},
+}
+
+for _, tt := range tests {
+t.Run(tt.name, func(t *testing.T) {
+if got := PlainText(tt.in); got != tt.want {
+t.Errorf("PlainText(%q) = %q, ожидалось %q", tt.in, got, tt.want)
+}
+})
+}
+}
@@ -0,0 +1,276 @@
+package markup
+
+import (
+"html"
+"strings"
+)
+
+// TokenType определяет вид токена HTML
+type TokenType int
+
+const (
+// TextToken — текст между тегами, сущности уже раскодированы
+TextToken TokenType = iota
+// StartTagToken — открывающий тег <a href="...">
+StartTagToken
+// EndTagToken — закрывающий тег </a>
+EndTagToken
+// SelfClosingTagToken — самозакрывающийся тег <br/>
+SelfClosingTagToken
+// CommentToken — комментарий, DOCTYPE или инструкция обработки
+CommentToken
+)
+
+// Attribute представляет атрибут тега
+type Attribute struct {
+Name  string
+Value string
+}
+
+// Token представляет элемент HTML-фрагмента
+type Token struct {
+Type TokenType
+// Data содержит текст для TextToken и имя тега в нижнем регистре для тегов
+Data  string
+Attrs []Attribute
+}
+
+// Attr возвращает значение атрибута и признак его наличия
+func (t Token) Attr(name string) (string, bool) {
+for _, attr := range t.Attrs {
+if attr.Name == name {
+return attr.Value, true
+}
+}
+return "", false
+}
+
+// rawTextElements содержат текст, который не разбирается как HTML
+var rawTextElements = map[string]bool{
+"script": true, "style": true, "textarea": true, "title": true,
+"xmp": true, "iframe": true, "noembed": true, "noframes": true,
+}
+
+// Tokenizer разбивает HTML-фрагмент на токены. Разбор нестрогий, как в
+// браузерах: некорректная разметка не приводит к ошибке, а "<", не
+// начинающий тег, считается текстом.
+type Tokenizer struct {
+input string
+pos   int
+// rawEnd — имя элемента, текст которого читается до его закрывающего тега
+rawEnd string
+}
+
+// NewTokenizer создает разборщик HTML-фрагмента
+func NewTokenizer(input string) *Tokenizer {
+return &Tokenizer{input: input}
+}
+
+// Next возвращает следующий токен; ok равен false в конце фрагмента
+func (z *Tokenizer) Next() (token Token, ok bool) {
+if z.pos >= len(z.input) {
+return Token{}, false
+}
+
+if z.rawEnd != "" {
+return z.rawText(), true
+}
+
+if token, end, ok := z.markup(z.pos); ok {
+z.pos = end
+if token.Type == StartTagToken && rawTextElements[token.Data] {
+z.rawEnd = token.Data
+}
+return token, true
+}
+
+// Текст продолжается до первого "<", с которого начинается разметка
+start := z.pos
+z.pos++
+for z.pos < len(z.input) {
+next := strings.IndexByte(z.input[z.pos:], '<')
+if next < 0 {
+z.pos = len(z.input)
+break
+}
+z.pos += next
+if _, _, ok := z.markup(z.pos); ok {
+break
+}
+z.pos++
+}
+return Token{Type: TextToken, Data: html.UnescapeString(z.input[start:z.pos])}, true
+}
+
+// rawText читает содержимое элемента вроде <script> до его закрывающего тега
+func (z *Tokenizer) rawText() Token {
+name := z.rawEnd
+z.rawEnd = ""
+
+start := z.pos
+z.pos = len(z.input)
+for i := start; i < len(z.input); i++ {
+next := strings.Index(z.input[i:], "</")
+if next < 0 {
+break
+}
+i += next
+// "</scripts" не закрывает <script>
+after := i + 2 + len(name)
+if after <= len(z.input) && strings.EqualFold(z.input[i+2:after], name) &&
+(after == len(z.input) || !isNameByte(z.input[after])) {
+z.pos = i
+break
+}
+}
+
+data := z.input[start:z.pos]
+if name == "textarea" || name == "title" {
+data = html.UnescapeString(data)
+}
+return Token{Type: TextToken, Data: data}
+}
+
+// markup разбирает тег, комментарий или объявление, начинающиеся в позиции at,
+// и возвращает позицию после него
+func (z *Tokenizer) markup(at int) (Token, int, bool) {
+input := z.input
+if at+1 >= len(input) || input[at] != '<' {
+return Token{}, 0, false
+}
+
+switch c := input[at+1]; {
+case strings.HasPrefix(input[at:], "<!--"):
+end := strings.Index(input[at+4:], "-->")
+if end < 0 {
+return Token{Type: CommentToken, Data: input[at+4:]}, len(input), true
+}
+return Token{Type: CommentToken, Data: input[at+4 : at+4+end]}, at + 4 + end + 3, true
+
+case strings.HasPrefix(input[at:], "<![CDATA["):
+// Секция CDATA встречается в XHTML-содержимом и является текстом
+end := strings.Index(input[at+9:], "]]>")
+if end < 0 {
+return Token{Type: TextToken, Data: input[at+9:]}, len(input), true
+}
+return Token{Type: TextToken, Data: input[at+9 : at+9+end]}, at + 9 + end + 3, true
+
+case c == '!' || c == '?':
+end := strings.IndexByte(input[at:], '>')
+if end < 0 {
+end = len(input) - at - 1
+}
+return Token{Type: CommentToken, Data: input[at+2 : at+end]}, at + end + 1, true
+
+case c == '/':
+if at+2 >= len(input) || !isLetter(input[at+2]) {
+return Token{}, 0, false
+}
+name, pos := readName(input, at+2)
+// Атрибуты закрывающего тега игнорируются
+end := strings.IndexByte(input[pos:], '>')
+if end < 0 {
+return Token{Type: EndTagToken, Data: name}, len(input), true
+}
+return Token{Type: EndTagToken, Data: name}, pos + end + 1, true
+
+case isLetter(c):
+name, pos := readName(input, at+1)
+token := Token{Type: StartTagToken, Data: name}
+for {
+pos = skipSpace(input, pos)
+if pos >= len(input) {
+return token, pos, true
+}
+switch input[pos] {
+case '>':
+return token, pos + 1, true
+case '/':
+if pos+1 < len(input) && input[pos+1] == '>' {
+token.Type = SelfClosingTagToken
+return token, pos + 2, true
+}
+pos++
+continue
+}
+
+var attr Attribute
+attr, pos = readAttribute(input, pos)
+if _, exists := token.Attr(attr.Name); !exists {
+token.Attrs = append(token.Attrs, attr)
+}
+}
+}
+
+return Token{}, 0, false
+}
+
+// readAttribute разбирает атрибут вида name, name=value, name="value" или name='value'
+func readAttribute(input string, pos int) (Attribute, int) {
+start := pos
+for pos < len(input) && !isSpace(input[pos]) && input[pos] != '=' && input[pos] != '>' &&
+!(input[pos] == '/' && pos+1 < len(input) && input[pos+1] == '>') {
+pos++
+}
+if pos == start {
+// Одиночный символ вроде "=" без имени пропускается
+pos++
+}
+attr := Attribute{Name: strings.ToLower(input[start:pos])}
+
+next := skipSpace(input, pos)
+if next >= len(input) || input[next] != '=' {
+return attr, pos
+}
+pos = skipSpace(input, next+1)
+if pos >= len(input) {
+return attr, pos
+}
+
+switch quote := input[pos]; quote {
+case '"', '\'':
+end := strings.IndexByte(input[pos+1:], quote)
+if end < 0 {
+attr.Value = html.UnescapeString(input[pos+1:])
+return attr, len(input)
+}
+attr.Value = html.UnescapeString(input[pos+1 : pos+1+end])
+return attr, pos + 1 + end + 1
+}
+
+start = pos
+for pos < len(input) && !isSpace(input[pos]) && input[pos] != '>' {
+pos++
+}
+attr.Value = html.UnescapeString(input[start:pos])
+return attr, pos
+}
+
+// readName читает имя тега и возвращает его в нижнем регистре
+func readName(input string, pos int) (string, int) {
+start := pos
+for pos < len(input) && isNameByte(input[pos]) {
+pos++
+}
+return strings.ToLower(input[start:pos]), pos
+}
+
+// skipSpace пропускает пробельные символы
+func skipSpace(input string, pos int) int {
+for pos < len(input) && isSpace(input[pos]) {
+pos++
+}
+return pos
+}
+
+func isSpace(c byte) bool {
+return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isLetter(c byte) bool {
+return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isNameByte(c byte) bool {
+return isLetter(c) || c >= '0' && c <= '9' || c == '-' || c == ':' || c == '_'
+}
@@ -0,0 +1,204 @@
+package opml
+
+import (
+"bytes"
+"encoding/xml"
+"fmt"
+"io"
+"rsshub/internal/adapters/parser"
+"strings"
+"time"
+)
+
+// Document представляет структуру OPML 1.0/2.0 документа
+type Document struct {
+XMLName xml.Name  `xml:"opml"`
+Version string    `xml:"version,attr"`
+Head    Head      `xml:"head"`
+Body    []Outline `xml:"body>outline"`
+}
+
+// Head представляет заголовок OPML документа
+type Head struct {
+Title       string `xml:"title,omitempty"`
+DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+// Outline представляет элемент outline: подписку или папку с вложенными элементами
+type Outline struct {
+Text    string `xml:"text,attr"`
+Title   string `xml:"title,attr,omitempty"`
+Type    string `xml:"type,attr,omitempty"`
+XMLURL  string `xml:"xmlUrl,attr,omitempty"`
+HTMLURL string `xml:"htmlUrl,attr,omitempty"`
+// URL используется вместо xmlUrl некоторыми программами с OPML 1.0
+URL      string    `xml:"url,attr,omitempty"`
+Outlines []Outline `xml:"outline"`
+}
+
+// Entry представляет подписку, извлеченную из OPML документа
+type Entry struct {
+Name     string
+URL      string
+Category string
+}
+
+// Parse читает OPML документ и возвращает подписки. Вложенные папки
+// сохраняются в поле Category через "/" (символы "/" и "\" в именах
+// папок экранируются обратной косой чертой). Элементы без адреса, не
+// являющиеся папками, также возвращаются, чтобы вызывающий код мог
+// сообщить о них как о некорректных. Документ в другой кодировке
+// (windows-1251, ISO-8859-1) перекодируется по XML-декларации.
+func Parse(r io.Reader) ([]Entry, error) {
+data, err := io.ReadAll(r)
+if err != nil {
+return nil, fmt.Errorf("ошибка чтения OPML: %w", err)
+}
+data, err = parser.XMLToUTF8(data)
+if err != nil {
+return nil, fmt.Errorf("ошибка разбора OPML: %w", err)
+}
+
+decoder := xml.NewDecoder(bytes.NewReader(data))
+// Документ уже перекодирован, кодировка в декларации больше не действует
+decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+return input, nil
+}
+
+var doc Document
+if err := decoder.Decode(&doc); err != nil {
+return nil, fmt.Errorf("ошибка разбора OPML: %w", err)
+}
+
+var entries []Entry
+collect(doc.Body, nil, &entries)
+return entries, nil
+}
+
+// collect рекурсивно обходит элементы outline
+func collect(outlines []Outline, folders []string, entries *[]Entry) {
+for _, outline := range outlines {
+name := strings.TrimSpace(outline.Title)
+if name == "" {
+name = strings.TrimSpace(outline.Text)
+}
+
+feedURL := outline.feedURL()
+
+// Элемент с вложенными элементами и без адреса считается папкой
+if feedURL == "" && len(outline.Outlines) > 0 {
+collect(outline.Outlines, append(folders[:len(folders):len(folders)], name), entries)
+continue
+}
+
+*entries = append(*entries, Entry{
+Name:     name,
+URL:      feedURL,
+Category: joinFolders(folders),
+})
+
+// Некоторые программы вкладывают подписки внутрь подписок
+if len(outline.Outlines) > 0 {
+collect(outline.Outlines, folders, entries)
+}
+}
+}
+
+// feedURL возвращает адрес канала: xmlUrl или атрибут url OPML 1.0.
+// Атрибут url элементов type="link" и type="include" указывает на страницу
+// или другой OPML-документ, а не на канал.
+func (o Outline) feedURL() string {
+if url := strings.TrimSpace(o.XMLURL); url != "" {
+return url
+}
+switch strings.ToLower(o.Type) {
+case "link", "include":
+return ""
+}
+return strings.TrimSpace(o.URL)
+}
+
+// joinFolders собирает путь папок в категорию, экранируя "/" и "\" в именах,
+// чтобы папка "News/Tech" не превратилась при экспорте в две вложенные
+func joinFolders(folders []string) string {
+escaped := make([]string, len(folders))
+for i, folder := range folders {
+folder = strings.ReplaceAll(folder, `\`, `\\`)
+escaped[i] = strings.ReplaceAll(folder, "/", `\/`)
+}
+return strings.Join(escaped, "/")
+}
+
+// splitFolders разбирает категорию, собранную joinFolders, обратно в путь папок
+func splitFolders(category string) []string {
+var folders []string
+var name strings.Builder
+for i := 0; i < len(category); i++ {
+switch c := category[i]; {
+case c == '\\' && i+1 < len(category):
+i++
+name.WriteByte(category[i])
+case c == '/':
+folders = append(folders, name.String())
+name.Reset()
+default:
+name.WriteByte(c)
+}
+}
+return append(folders, name.String())
+}
+
+// Write записывает подписки в виде OPML 2.0 документа. Категории вида
+// "Папка/Подпапка" превращаются во вложенные элементы outline.
+func Write(w io.Writer, title string, entries []Entry) error {
+doc := Document{
+Version: "2.0",
+Head: Head{
+Title:       title,
+DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+},
+}
+
+for _, entry := range entries {
+outline := Outline{
+Text:   entry.Name,
+Title:  entry.Name,
+Type:   "rss",
+XMLURL: entry.URL,
+}
+
+folder := &doc.Body
+if entry.Category != "" {
+for _, name := range splitFolders(entry.Category) {
+folder = findFolder(folder, name)
+}
+}
+*folder = append(*folder, outline)
+}
+
+if _, err := io.WriteString(w, xml.Header); err != nil {
+return err
+}
+
+encoder := xml.NewEncoder(w)
+encoder.Indent("", "  ")
+if err := encoder.Encode(doc); err != nil {
+return fmt.Errorf("ошибка записи OPML: %w", err)
+}
+
+_, err := io.WriteString(w, "\n")
+return err
+}
+
+// findFolder возвращает вложенные элементы папки с указанным именем, создавая ее при необходимости
+func findFolder(outlines *[]Outline, name string) *[]Outline {
+for i := range *outlines {
+outline := &(*outlines)[i]
+if outline.XMLURL == "" && outline.Text == name {
+return &outline.Outlines
+}
+}
+
+*outlines = append(*outlines, Outline{Text: name, Title: name})
+return &(*outlines)[len(*outlines)-1].Outlines
+}
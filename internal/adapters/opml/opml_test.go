@@ -0,0 +1,130 @@
+package opml
+
+import (
+"reflect"
+"strings"
+"testing"
+)
+
+func TestParse(t *testing.T) {
+tests := []struct {
+name string
+doc  string
+want []Entry
+}{
+{
+name: "OPML 2.0 с вложенными папками",
+doc: `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0"><head><title>Subscriptions</title></head><body>
+<outline text="Tech">
+<outline text="Go" title="Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom"/>
+<outline text="Languages">
+<outline text="Rust" type="rss" xmlUrl=" https://blog.rust-lang.org/feed.xml "/>
+</outline>
+</outline>
+<outline text="Top" type="rss" xmlUrl="https://example.com/top.xml"/>
+<outline text="Broken" type="rss"/>
+</body></opml>`,
+want: []Entry{
+{Name: "Go Blog", URL: "https://go.dev/blog/feed.atom", Category: "Tech"},
+{Name: "Rust", URL: "https://blog.rust-lang.org/feed.xml", Category: "Tech/Languages"},
+{Name: "Top", URL: "https://example.com/top.xml"},
+{Name: "Broken"},
+},
+},
+{
+name: "OPML 1.0 с атрибутом url",
+doc: `<opml version="1.0"><head><title>Old</title></head><body>
+<outline text="News">
+<outline text="Feed" type="rss" url="https://example.com/feed.xml"/>
+<outline text="Homepage" type="link" url="https://example.com/"/>
+</outline>
+</body></opml>`,
+want: []Entry{
+{Name: "Feed", URL: "https://example.com/feed.xml", Category: "News"},
+{Name: "Homepage", Category: "News"},
+},
+},
+{
+name: "windows-1251",
+doc: `<?xml version="1.0" encoding="windows-1251"?>
+<opml version="1.0"><head><title>x</title></head><body>
+<outline text="` + "\xcd\xee\xe2\xee\xf1\xf2\xe8" + `">
+<outline text="` + "\xcb\xe5\xed\xf2\xe0" + `" xmlUrl="https://example.com/news.xml"/>
+</outline>
+</body></opml>`,
+want: []Entry{{Name: "Лента", URL: "https://example.com/news.xml", Category: "Новости"}},
+},
+{
+name: "ISO-8859-1",
+doc: `<?xml version="1.0" encoding="ISO-8859-1"?>
+<opml version="1.1"><body><outline text="Caf` + "\xe9" + `" xmlUrl="https://example.com/cafe.xml"/></body></opml>`,
+want: []Entry{{Name: "Café", URL: "https://example.com/cafe.xml"}},
+},
+}
+
+for _, tt := range tests {
+t.Run(tt.name, func(t *testing.T) {
+got, err := Parse(strings.NewReader(tt.doc))
+if err != nil {
+t.Fatalf("неожиданная ошибка: %v", err)
+}
+if !reflect.DeepEqual(got, tt.want) {
+t.Errorf("получено  %+v\nожидалось %+v", got, tt.want)
+}
+})
+}
+}
+
+func TestParseInvalid(t *testing.T) {
+for _, doc := range []string{
+"not xml",
+`<?xml version="1.0" encoding="x-unknown"?><opml><body/></opml>`,
+} {
+if _, err := Parse(strings.NewReader(doc)); err == nil {
+t.Errorf("Parse(%q): ожидалась ошибка", doc)
+}
+}
+}
+
+func TestRoundTrip(t *testing.T) {
+doc := `<opml version="2.0"><body>
+<outline text="News/Tech">
+<outline text="A" xmlUrl="https://example.com/a.xml"/>
+<outline text="C:\Feeds">
+<outline text="B" xmlUrl="https://example.com/b.xml"/>
+</outline>
+</outline>
+<outline text="News">
+<outline text="Tech">
+<outline text="C" xmlUrl="https://example.com/c.xml"/>
+</outline>
+</outline>
+</body></opml>`
+
+first, err := Parse(strings.NewReader(doc))
+if err != nil {
+t.Fatalf("импорт: %v", err)
+}
+want := []Entry{
+{Name: "A", URL: "https://example.com/a.xml", Category: `News\/Tech`},
+{Name: "B", URL: "https://example.com/b.xml", Category: `News\/Tech/C:\\Feeds`},
+{Name: "C", URL: "https://example.com/c.xml", Category: "News/Tech"},
+}
+if !reflect.DeepEqual(first, want) {
+t.Fatalf("импорт: получено %+v\nожидалось %+v", first, want)
+}
+
+var buf strings.Builder
+if err := Write(&buf, "Subscriptions", first); err != nil {
+t.Fatalf("экспорт: %v", err)
+}
+
+second, err := Parse(strings.NewReader(buf.String()))
+if err != nil {
+t.Fatalf("повторный импорт: %v", err)
+}
+if !reflect.DeepEqual(second, first) {
+t.Errorf("повторный импорт: получено %+v\nожидалось %+v\n%s", second, first, buf.String())
+}
+}
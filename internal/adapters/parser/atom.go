@@ -0,0 +1,219 @@
+package parser
+
+import (
+"html"
+neturl "net/url"
+"rsshub/internal/adapters/markup"
+"rsshub/internal/domain"
+"strings"
+)
+
+// atomFeed представляет структуру Atom 1.0 документа
+type atomFeed struct {
+Base      string       `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+Lang      string       `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+Title     atomText     `xml:"title"`
+Subtitle  atomText     `xml:"subtitle"`
+Icon      string       `xml:"icon"`
+Logo      string       `xml:"logo"`
+Generator string       `xml:"generator"`
+Links     []atomLink   `xml:"link"`
+Authors   []atomPerson `xml:"author"`
+Entries   []atomEntry  `xml:"entry"`
+// Atom не определяет ttl, но модуль syndication встречается и в Atom-каналах
+channelHints
+}
+
+// atomEntry представляет запись Atom-канала
+type atomEntry struct {
+// Элементы Media RSS объявлены первыми: иначе media:content попал бы в поле Content
+itemMedia
+Base       string         `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+ID         string         `xml:"id"`
+Title      atomText       `xml:"title"`
+Links      []atomLink     `xml:"link"`
+Published  string         `xml:"published"`
+Updated    string         `xml:"updated"`
+Summary    atomText       `xml:"summary"`
+Content    atomText       `xml:"content"`
+Authors    []atomPerson   `xml:"author"`
+Categories []atomCategory `xml:"category"`
+}
+
+// atomCategory представляет категорию Atom-записи
+type atomCategory struct {
+Term  string `xml:"term,attr"`
+Label string `xml:"label,attr"`
+}
+
+// atomText представляет текстовую конструкцию Atom (text, html или xhtml)
+type atomText struct {
+Base     string `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+Type     string `xml:"type,attr"`
+Src      string `xml:"src,attr"`
+Text     string `xml:",chardata"`
+InnerXML string `xml:",innerxml"`
+}
+
+// atomLink представляет элемент link Atom-канала
+type atomLink struct {
+Href   string `xml:"href,attr"`
+Rel    string `xml:"rel,attr"`
+Type   string `xml:"type,attr"`
+Length string `xml:"length,attr"`
+}
+
+// atomPerson представляет автора Atom-канала
+type atomPerson struct {
+Name  string `xml:"name"`
+Email string `xml:"email"`
+}
+
+// value возвращает содержимое текстовой конструкции. Для содержимого,
+// вынесенного по ссылке (атрибут src), возвращается пустая строка.
+func (t atomText) value() string {
+if t.Src != "" {
+return ""
+}
+if t.Type == "xhtml" {
+return strings.TrimSpace(t.InnerXML)
+}
+return strings.TrimSpace(t.Text)
+}
+
+// html возвращает содержимое текстовой конструкции в виде HTML:
+// текст типа text экранируется
+func (t atomText) html() string {
+if t.Type == "" || t.Type == "text" {
+return html.EscapeString(t.value())
+}
+return t.value()
+}
+
+// text возвращает содержимое текстовой конструкции без разметки в одну строку:
+// заголовки типа html и xhtml (например, в каналах WordPress) содержат
+// сущности и теги
+func (t atomText) text() string {
+if t.Type == "" || t.Type == "text" {
+return t.value()
+}
+return strings.Join(strings.Fields(markup.PlainText(t.value())), " ")
+}
+
+// parseAtom разбирает Atom 1.0 документ
+func parseAtom(data []byte, base *neturl.URL) (*domain.ParsedFeed, error) {
+var doc atomFeed
+warning, err := unmarshalXML(data, &doc)
+if err != nil {
+return nil, err
+}
+
+feed := &domain.ParsedFeed{
+Title:       doc.Title.text(),
+Link:        alternateLink(doc.Links),
+Description: doc.Subtitle.html(),
+ImageURL:    strings.TrimSpace(doc.Logo),
+IconURL:     strings.TrimSpace(doc.Icon),
+Language:    strings.TrimSpace(doc.Lang),
+Generator:   strings.TrimSpace(doc.Generator),
+Hints:       doc.channelHints.toDomain(),
+Warning:     warning,
+}
+feedBase := xmlBase(base, doc.Base)
+prepareFeed(feed, feedBase)
+
+for _, entry := range doc.Entries {
+item := domain.FeedItem{
+GUID:        strings.TrimSpace(entry.ID),
+Title:       entry.Title.text(),
+Link:        alternateLink(entry.Links),
+Description: entry.Summary.html(),
+Content:     entry.Content.html(),
+PubDate:     strings.TrimSpace(entry.Published),
+Updated:     strings.TrimSpace(entry.Updated),
+Authors:     personNames(entry.Authors),
+Categories:  categoryNames(entry.Categories),
+}
+
+entryBase := xmlBase(feedBase, entry.Base)
+contentBase := xmlBase(entryBase, entry.Content.Base)
+descriptionBase := xmlBase(entryBase, entry.Summary.Base)
+
+// Если summary отсутствует, используем content
+if item.Description == "" {
+item.Description = item.Content
+descriptionBase = contentBase
+}
+
+// Если дата публикации не указана, используем дату обновления
+if item.PubDate == "" {
+item.PubDate = item.Updated
+}
+
+// Авторы записи наследуются от канала
+if len(item.Authors) == 0 {
+item.Authors = personNames(doc.Authors)
+}
+
+for _, link := range entry.Links {
+if link.Rel != "enclosure" || strings.TrimSpace(link.Href) == "" {
+continue
+}
+item.Enclosures = appendEnclosure(item.Enclosures, domain.Enclosure{
+URL:      strings.TrimSpace(link.Href),
+MIMEType: strings.TrimSpace(link.Type),
+Length:   parseLength(link.Length),
+})
+}
+entry.itemMedia.apply(&item)
+prepareItem(&item, entryBase, descriptionBase, contentBase)
+
+feed.Items = append(feed.Items, item)
+}
+
+return feed, nil
+}
+
+// alternateLink выбирает ссылку rel="alternate" (или ссылку без rel)
+func alternateLink(links []atomLink) string {
+for _, link := range links {
+if link.Rel == "" || link.Rel == "alternate" {
+return strings.TrimSpace(link.Href)
+}
+}
+// Вложение не может служить ссылкой на статью
+for _, link := range links {
+if link.Rel != "enclosure" {
+return strings.TrimSpace(link.Href)
+}
+}
+return ""
+}
+
+// categoryNames возвращает названия категорий: label, если указан, иначе term
+func categoryNames(categories []atomCategory) []string {
+names := make([]string, 0, len(categories))
+for _, category := range categories {
+name := category.Label
+if strings.TrimSpace(name) == "" {
+name = category.Term
+}
+names = append(names, name)
+}
+return uniqueNames(names)
+}
+
+// personNames возвращает имена авторов
+func personNames(persons []atomPerson) []string {
+var names []string
+for _, person := range persons {
+name := strings.TrimSpace(person.Name)
+if name == "" {
+name = strings.TrimSpace(person.Email)
+}
+if name != "" {
+names = append(names, name)
+}
+}
+return names
+}
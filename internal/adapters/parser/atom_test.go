@@ -0,0 +1,36 @@
+package parser
+
+import "testing"
+
+func TestParseAtomTitles(t *testing.T) {
+data := []byte(`<feed xmlns="http://www.w3.org/2005/Atom">
+<title type="html">Blog &lt;b&gt;News&lt;/b&gt;</title>
+<subtitle>Less &lt; more</subtitle>
+<entry><id>1</id><title type="html">It&amp;#8217;s &lt;em&gt;here&lt;/em&gt;</title></entry>
+<entry><id>2</id><title type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml">A <b>bold</b>
+title</div></title></entry>
+<entry><id>3</id><title>Plain &lt;tag&gt;</title></entry>
+</feed>`)
+
+feed, err := parseAtom(data, nil)
+if err != nil {
+t.Fatalf("неожиданная ошибка: %v", err)
+}
+
+if feed.Title != "Blog News" {
+t.Errorf("заголовок канала: получено %q", feed.Title)
+}
+if feed.Description != "Less < more" {
+t.Errorf("описание канала: получено %q", feed.Description)
+}
+
+want := []string{"It’s here", "A bold title", "Plain <tag>"}
+if len(feed.Items) != len(want) {
+t.Fatalf("ожидалось %d элемента, получено %d", len(want), len(feed.Items))
+}
+for i, title := range want {
+if feed.Items[i].Title != title {
+t.Errorf("заголовок записи %d: ожидалось %q, получено %q", i+1, title, feed.Items[i].Title)
+}
+}
+}
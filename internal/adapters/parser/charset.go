@@ -0,0 +1,155 @@
+package parser
+
+import (
+"bytes"
+"encoding/binary"
+"encoding/xml"
+"fmt"
+"io"
+"mime"
+"regexp"
+"strings"
+"unicode/utf16"
+"unicode/utf8"
+)
+
+// charmap задает символы Unicode для байтов 0x80–0xFF однобайтовой кодировки
+type charmap [128]rune
+
+// decode преобразует текст в однобайтовой кодировке в UTF-8
+func (m *charmap) decode(data []byte) []byte {
+result := make([]byte, 0, len(data)*2)
+for _, b := range data {
+if b < 0x80 {
+result = append(result, b)
+continue
+}
+result = utf8.AppendRune(result, m[b-0x80])
+}
+return result
+}
+
+// charmaps сопоставляет названия кодировок (в нижнем регистре) с таблицами.
+// ISO-8859-1 и US-ASCII, как и в браузерах, читаются как windows-1252:
+// серверы часто указывают их для текста в windows-1252.
+var charmaps = map[string]*charmap{
+"windows-1251": &windows1251, "cp1251": &windows1251, "x-cp1251": &windows1251, "win-1251": &windows1251,
+"windows-1252": &windows1252, "cp1252": &windows1252, "x-cp1252": &windows1252,
+"iso-8859-1": &windows1252, "iso8859-1": &windows1252, "iso_8859-1": &windows1252, "latin1": &windows1252,
+"l1": &windows1252, "us-ascii": &windows1252, "ascii": &windows1252,
+"koi8-r": &koi8r, "koi8r": &koi8r, "koi8": &koi8r, "cskoi8r": &koi8r,
+"koi8-u": &koi8u, "koi8u": &koi8u,
+"ibm866": &ibm866, "cp866": &ibm866, "866": &ibm866,
+"iso-8859-2": &iso88592, "iso8859-2": &iso88592, "iso_8859-2": &iso88592, "latin2": &iso88592, "l2": &iso88592,
+"iso-8859-5": &iso88595, "iso8859-5": &iso88595, "iso_8859-5": &iso88595, "cyrillic": &iso88595,
+"iso-8859-7": &iso88597, "iso8859-7": &iso88597, "iso_8859-7": &iso88597, "greek": &iso88597,
+"iso-8859-9": &iso88599, "iso8859-9": &iso88599, "iso_8859-9": &iso88599, "latin5": &iso88599, "l5": &iso88599,
+"iso-8859-15": &iso885915, "iso8859-15": &iso885915, "iso_8859-15": &iso885915, "latin9": &iso885915, "l9": &iso885915,
+}
+
+var (
+xmlDeclarationPattern = regexp.MustCompile(`^\s*<\?xml\s[^>]*?encoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+metaCharsetPattern    = regexp.MustCompile(`(?i)<meta\b[^>]*?charset\s*=\s*["']?([A-Za-z0-9._:-]+)`)
+)
+
+// metaCharsetLimit ограничивает начало HTML-страницы, в котором ищется <meta charset>
+const metaCharsetLimit = 1024
+
+// toUTF8 перекодирует документ в UTF-8. Кодировка определяется по BOM,
+// затем по параметру charset заголовка Content-Type, затем по XML-декларации
+// или <meta charset> HTML-страницы. Без указаний документ считается UTF-8.
+func toUTF8(data []byte, contentType string) ([]byte, error) {
+if data, ok := decodeBOM(data); ok {
+return data, nil
+}
+
+label := ""
+if _, params, err := mime.ParseMediaType(contentType); err == nil {
+label = params["charset"]
+}
+if label == "" {
+label = declaredCharset(data)
+}
+
+return decodeCharset(data, label)
+}
+
+// XMLToUTF8 перекодирует XML-документ, полученный не по HTTP (например,
+// OPML-файл), в UTF-8 по BOM или XML-декларации
+func XMLToUTF8(data []byte) ([]byte, error) {
+return toUTF8(data, "")
+}
+
+// decodeBOM перекодирует документ, начинающийся с метки порядка байтов
+func decodeBOM(data []byte) ([]byte, bool) {
+switch {
+case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+return data[3:], true
+case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+return decodeUTF16(data[2:], binary.BigEndian), true
+case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+return decodeUTF16(data[2:], binary.LittleEndian), true
+}
+return data, false
+}
+
+// declaredCharset возвращает кодировку из XML-декларации или <meta charset>
+func declaredCharset(data []byte) string {
+if match := xmlDeclarationPattern.FindSubmatch(data); match != nil {
+return string(match[1])
+}
+
+head := data
+if len(head) > metaCharsetLimit {
+head = head[:metaCharsetLimit]
+}
+if match := metaCharsetPattern.FindSubmatch(head); match != nil {
+return string(match[1])
+}
+return ""
+}
+
+// decodeCharset перекодирует документ из кодировки label в UTF-8
+func decodeCharset(data []byte, label string) ([]byte, error) {
+label = strings.ToLower(strings.Trim(strings.TrimSpace(label), `"'`))
+
+switch label {
+case "", "utf-8", "utf8":
+return data, nil
+case "utf-16be":
+return decodeUTF16(data, binary.BigEndian), nil
+case "utf-16le", "utf-16":
+// UTF-16 без BOM по умолчанию считается little-endian, как в браузерах
+return decodeUTF16(data, binary.LittleEndian), nil
+}
+
+if table, ok := charmaps[label]; ok {
+return table.decode(data), nil
+}
+return nil, fmt.Errorf("неподдерживаемая кодировка: %s", label)
+}
+
+// decodeUTF16 преобразует текст в UTF-16 в UTF-8
+func decodeUTF16(data []byte, order binary.ByteOrder) []byte {
+units := make([]uint16, 0, len(data)/2)
+for i := 0; i+1 < len(data); i += 2 {
+units = append(units, order.Uint16(data[i:]))
+}
+
+result := make([]byte, 0, len(data))
+for _, r := range utf16.Decode(units) {
+result = utf8.AppendRune(result, r)
+}
+return result
+}
+
+// newXMLDecoder создает декодер для документа, уже перекодированного в UTF-8.
+// XML-декларация при этом по-прежнему указывает исходную кодировку, поэтому
+// декодер принимает любую кодировку и читает документ без изменений.
+func newXMLDecoder(data []byte) *xml.Decoder {
+decoder := xml.NewDecoder(bytes.NewReader(data))
+decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+return input, nil
+}
+return decoder
+}
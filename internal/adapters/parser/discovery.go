@@ -0,0 +1,150 @@
+package parser
+
+import (
+"context"
+"html"
+neturl "net/url"
+"regexp"
+"rsshub/internal/domain"
+"strings"
+)
+
+// feedMediaTypes содержит MIME-типы каналов, указываемые в <link rel="alternate">
+var feedMediaTypes = map[string]bool{
+"application/rss+xml":   true,
+"application/atom+xml":  true,
+"application/rdf+xml":   true,
+"application/feed+json": true,
+}
+
+// commonFeedPaths содержит распространенные адреса каналов, которые
+// проверяются, если страница не объявляет каналы явно
+var commonFeedPaths = []string{
+"/feed",
+"/rss",
+"/rss.xml",
+"/feed.xml",
+"/atom.xml",
+"/index.xml",
+"/feed.json",
+}
+
+var (
+linkTagPattern   = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+attributePattern = regexp.MustCompile(`(?is)([a-z][a-z0-9_:-]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// DiscoverFeeds ищет каналы по адресу страницы. Если адрес уже указывает
+// на канал, он возвращается как единственный кандидат.
+func (p *RSSParser) DiscoverFeeds(ctx context.Context, pageURL string) ([]domain.DiscoveredFeed, error) {
+doc, err := p.fetchRaw(ctx, pageURL, domain.CacheValidators{})
+if err != nil {
+return nil, err
+}
+
+// Страница в неподдерживаемой кодировке не может быть каналом, но элементы
+// <link> записываются ASCII и ищутся в исходных байтах
+if body, err := toUTF8(doc.body, doc.contentType); err == nil {
+doc.body = body
+// Адрес уже указывает на канал
+if feed, err := p.parseDocument(doc.body, doc.contentType, doc.url); err == nil {
+return []domain.DiscoveredFeed{{URL: doc.url.String(), Title: feed.Title}}, nil
+}
+}
+
+candidates := linkedFeeds(doc.body, doc.url)
+if len(candidates) > 0 {
+return candidates, nil
+}
+
+// Проверяем распространенные адреса каналов относительно корня сайта
+for _, path := range commonFeedPaths {
+candidateURL := doc.url.ResolveReference(&neturl.URL{Path: path}).String()
+
+candidate, err := p.fetch(ctx, candidateURL, domain.CacheValidators{})
+if err != nil {
+continue
+}
+
+feed, err := p.parseDocument(candidate.body, candidate.contentType, candidate.url)
+if err != nil {
+continue
+}
+
+candidates = append(candidates, domain.DiscoveredFeed{
+URL:   candidate.url.String(),
+Title: feed.Title,
+})
+}
+
+return uniqueFeeds(candidates), nil
+}
+
+// linkedFeeds извлекает каналы из элементов <link rel="alternate"> HTML-страницы
+func linkedFeeds(page []byte, base *neturl.URL) []domain.DiscoveredFeed {
+var feeds []domain.DiscoveredFeed
+
+for _, tag := range linkTagPattern.FindAll(page, -1) {
+attrs := tagAttributes(string(tag))
+
+if !hasToken(attrs["rel"], "alternate") {
+continue
+}
+
+mediaType := strings.ToLower(strings.TrimSpace(attrs["type"]))
+if !feedMediaTypes[mediaType] || attrs["href"] == "" {
+continue
+}
+
+href, err := neturl.Parse(strings.TrimSpace(attrs["href"]))
+if err != nil {
+continue
+}
+
+feeds = append(feeds, domain.DiscoveredFeed{
+URL:   base.ResolveReference(href).String(),
+// Страница могла не перекодироваться в UTF-8
+Title: strings.ToValidUTF8(strings.TrimSpace(attrs["title"]), ""),
+Type:  mediaType,
+})
+}
+
+return uniqueFeeds(feeds)
+}
+
+// tagAttributes разбирает атрибуты HTML-тега
+func tagAttributes(tag string) map[string]string {
+attrs := make(map[string]string)
+for _, match := range attributePattern.FindAllStringSubmatch(tag, -1) {
+name := strings.ToLower(match[1])
+if _, exists := attrs[name]; exists {
+continue
+}
+attrs[name] = html.UnescapeString(match[2] + match[3] + match[4])
+}
+return attrs
+}
+
+// hasToken проверяет наличие значения в списке через пробел (например, rel="alternate home")
+func hasToken(list, token string) bool {
+for _, value := range strings.Fields(list) {
+if strings.EqualFold(value, token) {
+return true
+}
+}
+return false
+}
+
+// uniqueFeeds удаляет повторяющиеся адреса, сохраняя порядок
+func uniqueFeeds(feeds []domain.DiscoveredFeed) []domain.DiscoveredFeed {
+seen := make(map[string]bool)
+var result []domain.DiscoveredFeed
+for _, feed := range feeds {
+if seen[feed.URL] {
+continue
+}
+seen[feed.URL] = true
+result = append(result, feed)
+}
+return result
+}
@@ -0,0 +1,32 @@
+package parser
+
+import (
+"context"
+"net/http"
+"net/http/httptest"
+"testing"
+)
+
+func TestDiscoverFeedsUnsupportedCharset(t *testing.T) {
+server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+if r.URL.Path != "/" {
+http.NotFound(w, r)
+return
+}
+w.Header().Set("Content-Type", "text/html; charset=x-unknown")
+w.Write([]byte(`<html><head><title>Сайт</title>
+<link rel="alternate" type="application/rss+xml" title="News" href="/news.xml">
+</head><body>` + "\xff\xfe" + `</body></html>`))
+}))
+defer server.Close()
+
+cfg := DefaultConfig()
+cfg.HTTP.AllowInternal = true
+feeds, err := NewRSSParser(cfg).DiscoverFeeds(context.Background(), server.URL+"/")
+if err != nil {
+t.Fatalf("неожиданная ошибка: %v", err)
+}
+if len(feeds) != 1 || feeds[0].URL != server.URL+"/news.xml" || feeds[0].Title != "News" {
+t.Fatalf("получено %+v", feeds)
+}
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+"net/http"
+"rsshub/internal/domain"
+"strconv"
+"strings"
+"time"
+)
+
+// channelHints содержит рекомендации издателя о частоте опроса канала.
+// Встраивается в структуры каналов разных форматов. Числовые значения
+// читаются как строки, чтобы некорректное значение не ломало разбор документа.
+type channelHints struct {
+TTL             string   `xml:"ttl"`
+SkipHours       []string `xml:"skipHours>hour"`
+SkipDays        []string `xml:"skipDays>day"`
+UpdatePeriod    string   `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+UpdateFrequency string   `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+}
+
+// syndicationPeriods сопоставляет значения sy:updatePeriod с длительностью
+var syndicationPeriods = map[string]time.Duration{
+"hourly":  time.Hour,
+"daily":   24 * time.Hour,
+"weekly":  7 * 24 * time.Hour,
+"monthly": 30 * 24 * time.Hour,
+"yearly":  365 * 24 * time.Hour,
+}
+
+// weekdays сопоставляет названия дней из skipDays с time.Weekday
+var weekdays = map[string]time.Weekday{
+"sunday":    time.Sunday,
+"monday":    time.Monday,
+"tuesday":   time.Tuesday,
+"wednesday": time.Wednesday,
+"thursday":  time.Thursday,
+"friday":    time.Friday,
+"saturday":  time.Saturday,
+}
+
+// toDomain преобразует рекомендации в доменную модель, отбрасывая некорректные значения
+func (h channelHints) toDomain() domain.PollingHints {
+var hints domain.PollingHints
+
+if minutes, err := strconv.Atoi(strings.TrimSpace(h.TTL)); err == nil && minutes > 0 {
+hints.TTL = time.Duration(minutes) * time.Minute
+}
+
+if period, ok := syndicationPeriods[strings.ToLower(strings.TrimSpace(h.UpdatePeriod))]; ok {
+frequency, err := strconv.Atoi(strings.TrimSpace(h.UpdateFrequency))
+if err != nil || frequency <= 0 {
+frequency = 1
+}
+hints.UpdatePeriod = period / time.Duration(frequency)
+}
+
+for _, value := range h.SkipHours {
+hour, err := strconv.Atoi(strings.TrimSpace(value))
+// Некоторые каналы используют 24 вместо 0
+if err == nil && hour == 24 {
+hour = 0
+}
+if err == nil && hour >= 0 && hour <= 23 {
+hints.SkipHours = append(hints.SkipHours, hour)
+}
+}
+
+for _, value := range h.SkipDays {
+if day, ok := weekdays[strings.ToLower(strings.TrimSpace(value))]; ok {
+hints.SkipDays = append(hints.SkipDays, day)
+}
+}
+
+return hints
+}
+
+// cacheLifetime возвращает время жизни ответа по заголовкам Cache-Control и Expires
+func cacheLifetime(header http.Header) time.Duration {
+for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+directive = strings.ToLower(strings.TrimSpace(directive))
+if directive == "no-cache" || directive == "no-store" {
+return 0
+}
+if value, ok := strings.CutPrefix(directive, "max-age="); ok {
+seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+if err != nil || seconds <= 0 {
+return 0
+}
+return time.Duration(seconds) * time.Second
+}
+}
+
+expires := header.Get("Expires")
+if expires == "" {
+return 0
+}
+expiresAt, err := http.ParseTime(expires)
+if err != nil {
+return 0
+}
+
+// Отсчитываем от времени сервера, чтобы не зависеть от расхождения часов
+now := time.Now()
+if date, err := http.ParseTime(header.Get("Date")); err == nil {
+now = date
+}
+
+if lifetime := expiresAt.Sub(now); lifetime > 0 {
+return lifetime
+}
+return 0
+}
@@ -0,0 +1,194 @@
+package parser
+
+import (
+"encoding/json"
+"html"
+neturl "net/url"
+"rsshub/internal/domain"
+"strings"
+"time"
+)
+
+// jsonFeed представляет структуру документа JSON Feed 1.0/1.1
+type jsonFeed struct {
+Version     string           `json:"version"`
+Title       string           `json:"title"`
+HomePageURL string           `json:"home_page_url"`
+Description string           `json:"description"`
+Icon        string           `json:"icon"`
+Favicon     string           `json:"favicon"`
+Language    string           `json:"language"`
+Authors     []jsonFeedAuthor `json:"authors"`
+Author      *jsonFeedAuthor  `json:"author"`
+Items       []jsonFeedItem   `json:"items"`
+}
+
+// jsonFeedItem представляет элемент JSON Feed
+type jsonFeedItem struct {
+ID            json.RawMessage      `json:"id"`
+URL           string               `json:"url"`
+ExternalURL   string               `json:"external_url"`
+Title         string               `json:"title"`
+Image         string               `json:"image"`
+ContentHTML   string               `json:"content_html"`
+ContentText   string               `json:"content_text"`
+Summary       string               `json:"summary"`
+DatePublished string               `json:"date_published"`
+DateModified  string               `json:"date_modified"`
+Authors       []jsonFeedAuthor     `json:"authors"`
+Author        *jsonFeedAuthor      `json:"author"`
+Attachments   []jsonFeedAttachment `json:"attachments"`
+Tags          []string             `json:"tags"`
+}
+
+// jsonFeedAuthor представляет автора в JSON Feed
+type jsonFeedAuthor struct {
+Name string `json:"name"`
+URL  string `json:"url"`
+}
+
+// jsonFeedAttachment представляет вложение элемента JSON Feed
+type jsonFeedAttachment struct {
+URL               string  `json:"url"`
+MIMEType          string  `json:"mime_type"`
+Title             string  `json:"title"`
+SizeInBytes       int64   `json:"size_in_bytes"`
+DurationInSeconds float64 `json:"duration_in_seconds"`
+}
+
+// parseJSONFeed разбирает документ JSON Feed
+func parseJSONFeed(data []byte, base *neturl.URL) (*domain.ParsedFeed, error) {
+var doc jsonFeed
+if err := json.Unmarshal(data, &doc); err != nil {
+return nil, err
+}
+
+feed := &domain.ParsedFeed{
+Title:       strings.TrimSpace(doc.Title),
+Link:        strings.TrimSpace(doc.HomePageURL),
+Description: strings.TrimSpace(doc.Description),
+ImageURL:    strings.TrimSpace(doc.Icon),
+IconURL:     strings.TrimSpace(doc.Favicon),
+Language:    strings.TrimSpace(doc.Language),
+}
+prepareFeed(feed, base)
+
+// Поле author устарело в версии 1.1, но все еще встречается
+feedAuthors := jsonAuthorNames(doc.Authors, doc.Author)
+
+for _, entry := range doc.Items {
+item := domain.FeedItem{
+GUID:        jsonFeedID(entry.ID),
+Title:       strings.TrimSpace(entry.Title),
+Link:        strings.TrimSpace(entry.URL),
+Description: html.EscapeString(entry.Summary),
+PubDate:     strings.TrimSpace(entry.DatePublished),
+Updated:     strings.TrimSpace(entry.DateModified),
+Authors:     jsonAuthorNames(entry.Authors, entry.Author),
+Image:       strings.TrimSpace(entry.Image),
+Categories:  uniqueNames(entry.Tags),
+}
+
+if item.Link == "" {
+item.Link = strings.TrimSpace(entry.ExternalURL)
+}
+
+item.Content = entry.ContentHTML
+if item.Content == "" {
+item.Content = html.EscapeString(entry.ContentText)
+}
+
+// Если краткое описание отсутствует, используем содержимое
+if item.Description == "" {
+item.Description = item.Content
+}
+
+if item.PubDate == "" {
+item.PubDate = item.Updated
+}
+
+// Заголовок в JSON Feed необязателен (микроблоги), а статья без него не сохраняется
+if item.Title == "" {
+item.Title = fallbackTitle(entry)
+}
+
+if len(item.Authors) == 0 {
+item.Authors = feedAuthors
+}
+
+for _, attachment := range entry.Attachments {
+if strings.TrimSpace(attachment.URL) == "" {
+continue
+}
+item.Enclosures = append(item.Enclosures, domain.Enclosure{
+URL:      strings.TrimSpace(attachment.URL),
+MIMEType: attachment.MIMEType,
+Length:   attachment.SizeInBytes,
+Duration: time.Duration(attachment.DurationInSeconds * float64(time.Second)),
+})
+}
+
+prepareItem(&item, base, base, base)
+feed.Items = append(feed.Items, item)
+}
+
+return feed, nil
+}
+
+// fallbackTitle формирует заголовок из текста элемента или его ссылки
+func fallbackTitle(entry jsonFeedItem) string {
+const maxRunes = 80
+
+text := strings.Join(strings.Fields(entry.ContentText), " ")
+if text == "" {
+text = strings.Join(strings.Fields(entry.Summary), " ")
+}
+if text == "" {
+return strings.TrimSpace(entry.URL)
+}
+
+runes := []rune(text)
+if len(runes) > maxRunes {
+return string(runes[:maxRunes]) + "…"
+}
+return text
+}
+
+// jsonFeedID возвращает идентификатор элемента. Спецификация требует строку,
+// но некоторые генераторы записывают числа.
+func jsonFeedID(raw json.RawMessage) string {
+if len(raw) == 0 {
+return ""
+}
+
+var id string
+if err := json.Unmarshal(raw, &id); err == nil {
+return strings.TrimSpace(id)
+}
+
+var number json.Number
+if err := json.Unmarshal(raw, &number); err == nil {
+return number.String()
+}
+
+return ""
+}
+
+// jsonAuthorNames возвращает имена авторов
+func jsonAuthorNames(authors []jsonFeedAuthor, legacy *jsonFeedAuthor) []string {
+if len(authors) == 0 && legacy != nil {
+authors = append(authors, *legacy)
+}
+
+var names []string
+for _, author := range authors {
+name := strings.TrimSpace(author.Name)
+if name == "" {
+name = strings.TrimSpace(author.URL)
+}
+if name != "" {
+names = append(names, name)
+}
+}
+return names
+}
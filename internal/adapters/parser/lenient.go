@@ -0,0 +1,158 @@
+package parser
+
+import (
+"bytes"
+"encoding/xml"
+"fmt"
+"reflect"
+"unicode/utf8"
+)
+
+// unmarshalXML разбирает перекодированный в UTF-8 XML-документ в структуру v.
+// Если документ не является корректным XML, он исправляется и разбирается
+// повторно в нестрогом режиме: разобранная часть документа сохраняется,
+// а ошибка строгого разбора возвращается как предупреждение.
+func unmarshalXML(data []byte, v any) (warning string, err error) {
+strictErr := newXMLDecoder(data).Decode(v)
+if strictErr == nil {
+return "", nil
+}
+
+// Строгий разбор мог заполнить структуру частично
+reflect.ValueOf(v).Elem().SetZero()
+
+decoder := newLenientXMLDecoder(cleanXML(data))
+if err := decoder.Decode(v); err != nil {
+// Документ оборван или поврежден сильнее, чем исправляет нестрогий
+// режим: элементы до места ошибки уже разобраны
+return fmt.Sprintf("документ содержит ошибки XML и разобран частично: %v", strictErr), nil
+}
+return fmt.Sprintf("документ содержит ошибки XML: %v", strictErr), nil
+}
+
+// newLenientXMLDecoder создает декодер, который, как и браузеры, допускает
+// незакрытые и неправильно вложенные элементы, неизвестные сущности
+// и сущности HTML (&nbsp;, &mdash;), не объявленные в XML
+func newLenientXMLDecoder(data []byte) *xml.Decoder {
+decoder := newXMLDecoder(data)
+decoder.Strict = false
+decoder.Entity = xml.HTMLEntity
+return decoder
+}
+
+// cleanXML исправляет частые ошибки в документах перед нестрогим разбором:
+// удаляет текст перед первым элементом и недопустимые в XML символы,
+// заменяет некорректные последовательности UTF-8 и экранирует одиночные "&".
+// Секции CDATA и комментарии не изменяются, кроме удаления недопустимых символов.
+func cleanXML(data []byte) []byte {
+if start := bytes.IndexByte(data, '<'); start > 0 {
+data = data[start:]
+}
+
+result := make([]byte, 0, len(data)+len(data)/16)
+for i := 0; i < len(data); {
+switch {
+case bytes.HasPrefix(data[i:], []byte("<![CDATA[")):
+i = copyVerbatim(&result, data, i, "]]>")
+continue
+case bytes.HasPrefix(data[i:], []byte("<!--")):
+i = copyVerbatim(&result, data, i, "-->")
+continue
+case data[i] == '&':
+if isEntityReference(data[i+1:]) {
+result = append(result, '&')
+} else {
+result = append(result, "&amp;"...)
+}
+i++
+continue
+}
+
+// Некорректный байт UTF-8 декодируется как U+FFFD и записывается им
+r, size := utf8.DecodeRune(data[i:])
+if isXMLChar(r) {
+result = utf8.AppendRune(result, r)
+}
+i += size
+}
+return result
+}
+
+// copyVerbatim копирует фрагмент от позиции start до конца end включительно
+// и возвращает позицию после него. Недопустимые в XML символы удаляются.
+func copyVerbatim(result *[]byte, data []byte, start int, end string) int {
+stop := len(data)
+if i := bytes.Index(data[start:], []byte(end)); i >= 0 {
+stop = start + i + len(end)
+}
+for _, r := range string(data[start:stop]) {
+if isXMLChar(r) {
+*result = utf8.AppendRune(*result, r)
+}
+}
+return stop
+}
+
+// isEntityReference проверяет, что после "&" записана ссылка на сущность:
+// &name;, &#123; или &#x1F;
+func isEntityReference(data []byte) bool {
+end := bytes.IndexByte(data, ';')
+// Длина самых длинных имен сущностей HTML не превышает 32 символов
+if end <= 0 || end > 32 {
+return false
+}
+name := data[:end]
+
+if name[0] == '#' {
+digits := name[1:]
+hex := len(digits) > 0 && (digits[0] == 'x' || digits[0] == 'X')
+if hex {
+digits = digits[1:]
+}
+if len(digits) == 0 {
+return false
+}
+for _, c := range digits {
+if !(c >= '0' && c <= '9' || hex && (c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F')) {
+return false
+}
+}
+return true
+}
+
+for i, c := range name {
+letter := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+if !letter && (i == 0 || !(c >= '0' && c <= '9')) {
+return false
+}
+}
+return true
+}
+
+// isXMLChar проверяет, допустим ли символ в документе XML 1.0
+func isXMLChar(r rune) bool {
+return r == '\t' || r == '\n' || r == '\r' ||
+r >= 0x20 && r <= 0xD7FF ||
+r >= 0xE000 && r <= 0xFFFD ||
+r >= 0x10000 && r <= 0x10FFFF
+}
+
+// markupText представляет элемент с HTML-содержимым (description). Обычно
+// HTML экранирован или находится в CDATA и попадает в Text, но некоторые
+// каналы вставляют разметку без экранирования: тогда нестрогий разбор видит
+// в элементе дочерние элементы, и содержимое берется из Inner.
+type markupText struct {
+Text     string `xml:",chardata"`
+Inner    string `xml:",innerxml"`
+Children []struct {
+XMLName xml.Name
+} `xml:",any"`
+}
+
+// value возвращает HTML-содержимое элемента
+func (t markupText) value() string {
+if len(t.Children) > 0 {
+return t.Inner
+}
+return t.Text
+}
@@ -0,0 +1,58 @@
+package parser
+
+import (
+"strings"
+"testing"
+)
+
+func TestParseDocumentLenient(t *testing.T) {
+p := NewRSSParser(DefaultConfig())
+
+tests := []struct {
+name        string
+data        string
+description string
+}{
+{
+name:        "недопустимый символ перед корневым элементом",
+data:        "\x01<rss><channel><title>t</title><item><title>a</title><description>b</description></item></channel></rss>",
+description: "b",
+},
+{
+name:        "неэкранированный HTML в описании RSS",
+data:        "<rss><channel><title>t</title><item><title>a</title><description><p>hi<br>there</description></item></channel></rss>",
+description: "there",
+},
+{
+name: "неэкранированный HTML в описании RDF",
+data: `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/">` +
+`<channel><title>t</title></channel><item><title>a</title><description><b>bold</b> text</description></item></rdf:RDF>`,
+description: "bold",
+},
+{
+name:        "экранированный HTML в описании",
+data:        "<rss><channel><title>t</title><item><title>a</title><description>&lt;p&gt;hi&lt;/p&gt;</description></item></channel></rss>",
+description: "hi",
+},
+{
+name:        "HTML в CDATA",
+data:        "<rss><channel><title>t</title><item><title>a</title><description><![CDATA[<p>hi &amp; bye</p>]]></description></item></channel></rss>",
+description: "hi & bye",
+},
+}
+
+for _, tt := range tests {
+t.Run(tt.name, func(t *testing.T) {
+feed, err := p.parseDocument([]byte(tt.data), "application/xml", nil)
+if err != nil {
+t.Fatalf("неожиданная ошибка: %v", err)
+}
+if len(feed.Items) != 1 {
+t.Fatalf("ожидался 1 элемент, получено %d", len(feed.Items))
+}
+if !strings.Contains(feed.Items[0].DescriptionText, tt.description) {
+t.Fatalf("описание %q не содержит %q", feed.Items[0].DescriptionText, tt.description)
+}
+})
+}
+}
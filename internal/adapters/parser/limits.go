@@ -0,0 +1,135 @@
+package parser
+
+import (
+"compress/gzip"
+"encoding/xml"
+"fmt"
+"io"
+"rsshub/internal/domain"
+"strings"
+)
+
+// limitReader возвращает domain.FeedLimitError, если из потока прочитано
+// больше limit байт. Нулевой limit не ограничивает чтение.
+type limitReader struct {
+r     io.Reader
+limit int64
+read  int64
+what  string
+}
+
+// Read читает данные из потока
+func (l *limitReader) Read(p []byte) (int, error) {
+n, err := l.r.Read(p)
+l.read += int64(n)
+if l.limit > 0 && l.read > l.limit {
+return n, &domain.FeedLimitError{Limit: l.what, Max: l.limit}
+}
+return n, err
+}
+
+// readBody читает тело ответа, распаковывая его, если сервер сжал ответ gzip.
+// Ограничение maxBodySize действует и на сжатое, и на распакованное тело:
+// небольшой сжатый ответ может распаковываться в гигабайты (gzip-бомба).
+func (p *RSSParser) readBody(body io.Reader, contentEncoding string) ([]byte, error) {
+body = &limitReader{r: body, limit: p.maxBodySize, what: "размер ответа"}
+
+switch encoding := strings.ToLower(strings.TrimSpace(contentEncoding)); encoding {
+case "", "identity":
+case "gzip", "x-gzip":
+gz, err := gzip.NewReader(body)
+if err != nil {
+return nil, fmt.Errorf("ошибка распаковки ответа: %w", err)
+}
+defer gz.Close()
+body = &limitReader{r: gz, limit: p.maxBodySize, what: "размер распакованного ответа"}
+default:
+return nil, fmt.Errorf("неподдерживаемое сжатие ответа: %s", encoding)
+}
+
+return io.ReadAll(body)
+}
+
+// voidHTMLElements — пустые элементы HTML, которые часто встречаются
+// незакрытыми в неэкранированных описаниях. Элементы link и source сюда
+// не входят: в RSS у них есть содержимое.
+var voidHTMLElements = []string{"br", "hr", "img", "input", "meta", "area", "col", "embed", "param", "track", "wbr"}
+
+// checkXMLLimits проверяет глубину вложенности элементов и количество элементов
+// канала (item, entry) до разбора документа в структуры. Документ проверяется
+// в том виде, в котором его разбирает нестрогий режим (после cleanXML): иначе
+// недопустимый символ в начале документа прерывал бы проверку, а разбор
+// исправленного документа проходил бы без ограничений. Синтаксические ошибки
+// здесь не проверяются: они обрабатываются при разборе, после которого
+// количество элементов проверяется повторно.
+func (p *RSSParser) checkXMLLimits(data []byte) error {
+decoder := newLenientXMLDecoder(cleanXML(data))
+// Незакрытые элементы HTML (<br>, <img>) в неэкранированном описании
+// не должны увеличивать глубину
+decoder.AutoClose = voidHTMLElements
+
+depth, items := 0, 0
+for {
+token, err := decoder.Token()
+if err != nil {
+return nil
+}
+
+switch element := token.(type) {
+case xml.StartElement:
+depth++
+if p.maxDepth > 0 && depth > p.maxDepth {
+return &domain.FeedLimitError{Limit: "глубина вложенности элементов", Max: int64(p.maxDepth)}
+}
+if name := strings.ToLower(element.Name.Local); name == "item" || name == "entry" {
+items++
+if p.maxItems > 0 && items > p.maxItems {
+return &domain.FeedLimitError{Limit: "количество элементов", Max: int64(p.maxItems)}
+}
+}
+case xml.EndElement:
+depth--
+}
+}
+}
+
+// checkJSONDepth проверяет глубину вложенности объектов и массивов JSON
+func (p *RSSParser) checkJSONDepth(data []byte) error {
+if p.maxDepth <= 0 {
+return nil
+}
+
+depth := 0
+inString, escaped := false, false
+for _, c := range data {
+switch {
+case inString:
+switch {
+case escaped:
+escaped = false
+case c == '\\':
+escaped = true
+case c == '"':
+inString = false
+}
+case c == '"':
+inString = true
+case c == '{' || c == '[':
+depth++
+if depth > p.maxDepth {
+return &domain.FeedLimitError{Limit: "глубина вложенности элементов", Max: int64(p.maxDepth)}
+}
+case c == '}' || c == ']':
+depth--
+}
+}
+return nil
+}
+
+// checkItemCount проверяет количество элементов разобранного канала
+func (p *RSSParser) checkItemCount(feed *domain.ParsedFeed) error {
+if p.maxItems > 0 && len(feed.Items) > p.maxItems {
+return &domain.FeedLimitError{Limit: "количество элементов", Max: int64(p.maxItems)}
+}
+return nil
+}
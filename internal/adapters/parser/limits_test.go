@@ -0,0 +1,63 @@
+package parser
+
+import (
+"errors"
+"rsshub/internal/domain"
+"strings"
+"testing"
+)
+
+func nestedRSS(prefix string, depth int) []byte {
+return []byte("<rss><channel><title>" + prefix + "</title>" +
+strings.Repeat("<x>", depth) + strings.Repeat("</x>", depth) +
+"<item><title>one</title></item></channel></rss>")
+}
+
+func TestCheckXMLLimitsDepth(t *testing.T) {
+p := NewRSSParser(DefaultConfig())
+
+tests := []struct {
+name string
+data []byte
+}{
+{"корректный документ", nestedRSS("ab", 500)},
+// Недопустимый символ прерывает нестрогий разбор исходного документа,
+// но удаляется перед разбором в структуры
+{"недопустимый символ", nestedRSS("a\x01b", 500)},
+{"текст перед корневым элементом", append([]byte("\x01junk"), nestedRSS("ab", 500)...)},
+}
+
+for _, tt := range tests {
+t.Run(tt.name, func(t *testing.T) {
+_, err := p.parseDocument(tt.data, "application/rss+xml", nil)
+var limitErr *domain.FeedLimitError
+if !errors.As(err, &limitErr) {
+t.Fatalf("ожидалась ошибка FeedLimitError, получено %v", err)
+}
+})
+}
+}
+
+func TestCheckXMLLimitsItems(t *testing.T) {
+cfg := DefaultConfig()
+cfg.MaxItems = 2
+p := NewRSSParser(cfg)
+
+data := []byte("<rss><channel><title>a\x01b</title>" +
+strings.Repeat("<item><title>x</title></item>", 3) + "</channel></rss>")
+_, err := p.parseDocument(data, "application/rss+xml", nil)
+var limitErr *domain.FeedLimitError
+if !errors.As(err, &limitErr) {
+t.Fatalf("ожидалась ошибка FeedLimitError, получено %v", err)
+}
+
+data = []byte("<rss><channel><title>a\x01b</title>" +
+strings.Repeat("<item><title>x</title></item>", 2) + "</channel></rss>")
+feed, err := p.parseDocument(data, "application/rss+xml", nil)
+if err != nil {
+t.Fatalf("неожиданная ошибка: %v", err)
+}
+if len(feed.Items) != 2 {
+t.Fatalf("ожидалось 2 элемента, получено %d", len(feed.Items))
+}
+}
@@ -0,0 +1,158 @@
+package parser
+
+import (
+"rsshub/internal/domain"
+"strconv"
+"strings"
+"time"
+)
+
+// itemMedia содержит вложения элемента из модулей Media RSS и iTunes.
+// Встраивается в структуры элементов RSS и Atom. Числовые значения
+// читаются как строки, чтобы некорректное значение не ломало разбор документа.
+type itemMedia struct {
+MediaContents   []mediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+MediaThumbnails []mediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+MediaGroups     []mediaGroup     `xml:"http://search.yahoo.com/mrss/ group"`
+ITunesDuration  string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+ITunesImage     struct {
+Href string `xml:"href,attr"`
+} `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+ITunesEpisode string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd episode"`
+ITunesSeason  string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd season"`
+}
+
+// mediaContent представляет элемент media:content
+type mediaContent struct {
+URL      string `xml:"url,attr"`
+Type     string `xml:"type,attr"`
+Medium   string `xml:"medium,attr"`
+FileSize string `xml:"fileSize,attr"`
+Duration string `xml:"duration,attr"`
+}
+
+// mediaThumbnail представляет элемент media:thumbnail
+type mediaThumbnail struct {
+URL string `xml:"url,attr"`
+}
+
+// mediaGroup представляет элемент media:group с альтернативными версиями вложения
+type mediaGroup struct {
+Contents   []mediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+Thumbnails []mediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+// rssEnclosure представляет элемент enclosure RSS 2.0
+type rssEnclosure struct {
+URL    string `xml:"url,attr"`
+Type   string `xml:"type,attr"`
+Length string `xml:"length,attr"`
+}
+
+// apply дополняет элемент вложениями, изображением и номером эпизода.
+// Вложения, уже найденные в основных элементах формата, имеют приоритет.
+func (m itemMedia) apply(item *domain.FeedItem) {
+contents := m.MediaContents
+thumbnails := m.MediaThumbnails
+for _, group := range m.MediaGroups {
+contents = append(contents, group.Contents...)
+thumbnails = append(thumbnails, group.Thumbnails...)
+}
+
+for _, content := range contents {
+if strings.TrimSpace(content.URL) == "" {
+continue
+}
+// Изображения в media:content используются как обложка, а не вложение
+if strings.EqualFold(content.Medium, "image") {
+if item.Image == "" {
+item.Image = strings.TrimSpace(content.URL)
+}
+continue
+}
+item.Enclosures = appendEnclosure(item.Enclosures, domain.Enclosure{
+URL:      strings.TrimSpace(content.URL),
+MIMEType: strings.TrimSpace(content.Type),
+Length:   parseLength(content.FileSize),
+Duration: parseMediaDuration(content.Duration),
+})
+}
+
+// itunes:duration относится к основному вложению эпизода
+if duration := parseMediaDuration(m.ITunesDuration); duration > 0 && len(item.Enclosures) > 0 {
+if item.Enclosures[0].Duration == 0 {
+item.Enclosures[0].Duration = duration
+}
+}
+
+if item.Image == "" {
+item.Image = strings.TrimSpace(m.ITunesImage.Href)
+}
+for _, thumbnail := range thumbnails {
+if item.Image != "" {
+break
+}
+item.Image = strings.TrimSpace(thumbnail.URL)
+}
+
+item.Episode = parsePositive(m.ITunesEpisode)
+item.Season = parsePositive(m.ITunesSeason)
+}
+
+// appendEnclosure добавляет вложение, объединяя его с уже найденным вложением с тем же адресом
+func appendEnclosure(enclosures []domain.Enclosure, enclosure domain.Enclosure) []domain.Enclosure {
+for i := range enclosures {
+existing := &enclosures[i]
+if existing.URL != enclosure.URL {
+continue
+}
+if existing.MIMEType == "" {
+existing.MIMEType = enclosure.MIMEType
+}
+if existing.Length == 0 {
+existing.Length = enclosure.Length
+}
+if existing.Duration == 0 {
+existing.Duration = enclosure.Duration
+}
+return enclosures
+}
+return append(enclosures, enclosure)
+}
+
+// parseLength разбирает размер вложения в байтах. Многие каналы указывают 0 или мусор.
+func parseLength(value string) int64 {
+length, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+if err != nil || length < 0 {
+return 0
+}
+return length
+}
+
+// parsePositive разбирает положительное целое число (номер эпизода или сезона)
+func parsePositive(value string) int {
+number, err := strconv.Atoi(strings.TrimSpace(value))
+if err != nil || number < 0 {
+return 0
+}
+return number
+}
+
+// parseMediaDuration разбирает длительность в секундах ("3600", "3600.5")
+// или в формате itunes:duration ("1:02:03", "62:03")
+func parseMediaDuration(value string) time.Duration {
+value = strings.TrimSpace(value)
+if value == "" {
+return 0
+}
+
+var seconds float64
+for _, part := range strings.Split(value, ":") {
+number, err := strconv.ParseFloat(part, 64)
+if err != nil || number < 0 {
+return 0
+}
+seconds = seconds*60 + number
+}
+return time.Duration(seconds * float64(time.Second))
+}
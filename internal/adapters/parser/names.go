@@ -0,0 +1,35 @@
+package parser
+
+import (
+"regexp"
+"strings"
+)
+
+// rssAuthorPattern соответствует author RSS 2.0 вида "user@example.com (Имя Автора)"
+var rssAuthorPattern = regexp.MustCompile(`^\S+@\S+\s*\((.+)\)$`)
+
+// rssAuthorName извлекает имя автора из элемента author RSS 2.0. Если имя
+// не указано, возвращается адрес электронной почты.
+func rssAuthorName(author string) string {
+author = strings.TrimSpace(author)
+if match := rssAuthorPattern.FindStringSubmatch(author); match != nil {
+return strings.TrimSpace(match[1])
+}
+return author
+}
+
+// uniqueNames удаляет пустые и повторяющиеся без учета регистра имена, сохраняя порядок
+func uniqueNames(names []string) []string {
+seen := make(map[string]bool)
+var result []string
+for _, name := range names {
+name = strings.Join(strings.Fields(name), " ")
+key := strings.ToLower(name)
+if name == "" || seen[key] {
+continue
+}
+seen[key] = true
+result = append(result, name)
+}
+return result
+}
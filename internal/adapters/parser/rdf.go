@@ -0,0 +1,84 @@
+package parser
+
+import (
+neturl "net/url"
+"rsshub/internal/domain"
+"strings"
+)
+
+// rdfDocument представляет структуру RSS 1.0 (RDF Site Summary) документа.
+// В отличие от RSS 2.0 элементы item находятся рядом с channel, а не внутри него.
+type rdfDocument struct {
+Base    string `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+Channel struct {
+Base string `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+// dc:title объявлен раньше title, чтобы не перезаписывать основной заголовок
+DCTitle     string `xml:"http://purl.org/dc/elements/1.1/ title"`
+Title       string `xml:"title"`
+Link        string `xml:"link"`
+Description string `xml:"description"`
+Language    string `xml:"http://purl.org/dc/elements/1.1/ language"`
+channelHints
+} `xml:"channel"`
+// Как и item, элемент image находится рядом с channel
+Image struct {
+URL string `xml:"url"`
+} `xml:"image"`
+Items []rdfItem `xml:"item"`
+}
+
+// rdfItem представляет элемент RSS 1.0 канала
+type rdfItem struct {
+Base        string     `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+About       string     `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+Title       string     `xml:"title"`
+Link        string     `xml:"link"`
+Description markupText `xml:"description"`
+Date        string     `xml:"http://purl.org/dc/elements/1.1/ date"`
+Creators    []string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+Subjects    []string   `xml:"http://purl.org/dc/elements/1.1/ subject"`
+Content     string     `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+}
+
+// parseRDF разбирает RSS 1.0 (RDF) документ
+func parseRDF(data []byte, base *neturl.URL) (*domain.ParsedFeed, error) {
+var doc rdfDocument
+warning, err := unmarshalXML(data, &doc)
+if err != nil {
+return nil, err
+}
+
+feed := &domain.ParsedFeed{
+Title:       strings.TrimSpace(doc.Channel.Title),
+Link:        strings.TrimSpace(doc.Channel.Link),
+Description: strings.TrimSpace(doc.Channel.Description),
+ImageURL:    strings.TrimSpace(doc.Image.URL),
+Language:    strings.TrimSpace(doc.Channel.Language),
+Hints:       doc.Channel.channelHints.toDomain(),
+Warning:     warning,
+}
+if feed.Title == "" {
+feed.Title = strings.TrimSpace(doc.Channel.DCTitle)
+}
+// Элементы находятся рядом с channel, поэтому xml:base канала на них не действует
+documentBase := xmlBase(base, doc.Base)
+prepareFeed(feed, xmlBase(documentBase, doc.Channel.Base))
+
+for _, item := range doc.Items {
+parsed := domain.FeedItem{
+GUID:        strings.TrimSpace(item.About),
+Title:       strings.TrimSpace(item.Title),
+Link:        strings.TrimSpace(item.Link),
+Description: item.Description.value(),
+Content:     strings.TrimSpace(item.Content),
+PubDate:     strings.TrimSpace(item.Date),
+Authors:     uniqueNames(item.Creators),
+Categories:  uniqueNames(item.Subjects),
+}
+itemBase := xmlBase(documentBase, item.Base)
+prepareItem(&parsed, itemBase, itemBase, itemBase)
+feed.Items = append(feed.Items, parsed)
+}
+
+return feed, nil
+}
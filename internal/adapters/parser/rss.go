@@ -1,38 +1,366 @@
 package parser
 
 import (
+"bytes"
+"context"
 "encoding/xml"
+"errors"
+"fmt"
 "io"
+"mime"
 "net/http"
+neturl "net/url"
+"rsshub/internal/adapters/httpclient"
 "rsshub/internal/domain"
+"strings"
+"time"
 )
 
+// Config описывает параметры парсера
+type Config struct {
+HTTP httpclient.Config
+// MaxBodySize ограничивает размер загружаемого документа в байтах,
+// в том числе после распаковки
+MaxBodySize int64
+// MaxItems ограничивает количество элементов в канале
+MaxItems int
+// MaxDepth ограничивает глубину вложенности элементов документа
+MaxDepth int
+}
+
+// DefaultConfig возвращает конфигурацию парсера по умолчанию
+func DefaultConfig() Config {
+return Config{
+HTTP:        httpclient.DefaultConfig(),
+MaxBodySize: 10 << 20,
+MaxItems:    5000,
+MaxDepth:    100,
+}
+}
+
 // RSSParser реализует интерфейс domain.RSSParser
-type RSSParser struct{}
+type RSSParser struct {
+client      *http.Client
+maxBodySize int64
+maxItems    int
+maxDepth    int
+}
 
 // NewRSSParser создает новый экземпляр RSSParser
-func NewRSSParser() *RSSParser {
-return &RSSParser{}
+func NewRSSParser(cfg Config) *RSSParser {
+return &RSSParser{
+client:      httpclient.New(cfg.HTTP),
+maxBodySize: cfg.MaxBodySize,
+maxItems:    cfg.MaxItems,
+maxDepth:    cfg.MaxDepth,
+}
 }
 
-// ParseFeed выполняет HTTP-запрос по URL и парсит RSS
-func (p *RSSParser) ParseFeed(url string) (*domain.RSS, error) {
-var rssparsed domain.RSS
-resp, err := http.Get(url)
+// ParseFeed выполняет HTTP-запрос по URL и парсит канал (RSS 2.0, RSS 1.0, Atom 1.0 или JSON Feed).
+// Переданные валидаторы отправляются в заголовках If-None-Match и If-Modified-Since.
+// Документ, превышающий ограничения размера, количества элементов или глубины
+// вложенности, отклоняется с ошибкой domain.FeedLimitError.
+func (p *RSSParser) ParseFeed(ctx context.Context, url string, validators domain.CacheValidators) (*domain.ParsedFeed, error) {
+doc, err := p.fetch(ctx, url, validators)
+if err != nil {
+return nil, err
+}
+
+feed, err := p.parseDocument(doc.body, doc.contentType, doc.url)
+if err != nil {
+return nil, err
+}
+
+feed.Validators = doc.validators
+feed.Hints.CacheMaxAge = doc.cacheMaxAge
+if feed.IconURL == "" {
+feed.IconURL = faviconURL(feed.Link)
+}
+return feed, nil
+}
+
+// faviconURL возвращает стандартный адрес значка сайта (/favicon.ico)
+func faviconURL(siteURL string) string {
+site, err := neturl.Parse(siteURL)
+if err != nil || site.Host == "" || (site.Scheme != "http" && site.Scheme != "https") {
+return ""
+}
+return site.Scheme + "://" + site.Host + "/favicon.ico"
+}
+
+// document представляет загруженный по HTTP документ
+type document struct {
+body        []byte
+contentType string
+url         *neturl.URL
+validators  domain.CacheValidators
+cacheMaxAge time.Duration
+}
+
+// fetch загружает документ по URL, выполняя условный GET при наличии валидаторов,
+// и перекодирует его в UTF-8
+func (p *RSSParser) fetch(ctx context.Context, url string, validators domain.CacheValidators) (*document, error) {
+doc, err := p.fetchRaw(ctx, url, validators)
+if err != nil {
+return nil, err
+}
+if doc.body, err = toUTF8(doc.body, doc.contentType); err != nil {
+return nil, err
+}
+return doc, nil
+}
+
+// fetchRaw загружает документ по URL без перекодирования
+func (p *RSSParser) fetchRaw(ctx context.Context, url string, validators domain.CacheValidators) (*document, error) {
+req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+if err != nil {
+return nil, err
+}
+if validators.ETag != "" {
+req.Header.Set("If-None-Match", validators.ETag)
+}
+if validators.LastModified != "" {
+req.Header.Set("If-Modified-Since", validators.LastModified)
+}
+// Сжатие запрашивается явно, чтобы ограничить размер распакованного ответа
+req.Header.Set("Accept-Encoding", "gzip")
+
+resp, err := p.client.Do(req)
 if err != nil {
 return nil, err
 }
 defer resp.Body.Close()
 
-respRead, err := io.ReadAll(resp.Body)
+if resp.StatusCode == http.StatusNotModified {
+return nil, domain.ErrNotModified
+}
+
+if resp.StatusCode < 200 || resp.StatusCode > 299 {
+return nil, fmt.Errorf("неожиданный HTTP-статус: %s", resp.Status)
+}
+
+body, err := p.readBody(resp.Body, resp.Header.Get("Content-Encoding"))
 if err != nil {
 return nil, err
 }
 
-err = xml.Unmarshal(respRead, &rssparsed)
+return &document{
+body:        body,
+contentType: resp.Header.Get("Content-Type"),
+// После редиректов итоговый адрес может отличаться от исходного
+url: resp.Request.URL,
+validators: domain.CacheValidators{
+ETag:         resp.Header.Get("ETag"),
+LastModified: resp.Header.Get("Last-Modified"),
+},
+cacheMaxAge: cacheLifetime(resp.Header),
+}, nil
+}
+
+// parseDocument определяет формат документа по Content-Type и содержимому и разбирает его.
+// Относительные адреса разрешаются относительно base — адреса документа.
+func (p *RSSParser) parseDocument(data []byte, contentType string, base *neturl.URL) (*domain.ParsedFeed, error) {
+if isJSONDocument(data, contentType) {
+if err := p.checkJSONDepth(data); err != nil {
+return nil, err
+}
+feed, err := parseJSONFeed(data, base)
 if err != nil {
 return nil, err
 }
+if err := p.checkItemCount(feed); err != nil {
+return nil, err
+}
+return feed, nil
+}
+
+if err := p.checkXMLLimits(data); err != nil {
+return nil, err
+}
+
+root, err := rootElement(data)
+if err != nil {
+return nil, err
+}
+
+var feed *domain.ParsedFeed
+switch root {
+case "rss":
+feed, err = parseRSS(data, base)
+case "feed":
+feed, err = parseAtom(data, base)
+case "rdf":
+feed, err = parseRDF(data, base)
+default:
+return nil, fmt.Errorf("неподдерживаемый формат канала: <%s>", root)
+}
+if err != nil {
+return nil, err
+}
+
+// Поврежденный документ без единого разобранного элемента считается ошибкой
+if feed.Warning != "" && len(feed.Items) == 0 {
+return nil, errors.New(feed.Warning)
+}
+if err := p.checkItemCount(feed); err != nil {
+return nil, err
+}
+return feed, nil
+}
+
+// isJSONDocument проверяет, является ли документ JSON Feed.
+// Серверы часто отдают каналы с неточным Content-Type, поэтому
+// дополнительно проверяется первый значимый символ документа.
+func isJSONDocument(data []byte, contentType string) bool {
+mediaType, _, err := mime.ParseMediaType(contentType)
+if err == nil && (mediaType == "application/feed+json" || mediaType == "application/json") {
+return true
+}
+
+trimmed := bytes.TrimLeft(data, " \t\r\n\ufeff")
+return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// rootElement возвращает локальное имя корневого элемента XML-документа.
+// Документ разбирается в нестрогом режиме: ошибки после корневого элемента
+// обрабатываются при разборе документа.
+func rootElement(data []byte) (string, error) {
+root, err := firstElement(data)
+if err != nil {
+// Недопустимые символы перед корневым элементом удаляются так же,
+// как при нестрогом разборе документа
+return firstElement(cleanXML(data))
+}
+return root, nil
+}
+
+// firstElement возвращает имя первого элемента документа в нижнем регистре
+func firstElement(data []byte) (string, error) {
+decoder := newLenientXMLDecoder(data)
+for {
+token, err := decoder.Token()
+if err != nil {
+if errors.Is(err, io.EOF) {
+return "", fmt.Errorf("документ не содержит корневого элемента")
+}
+return "", err
+}
+if start, ok := token.(xml.StartElement); ok {
+return strings.ToLower(start.Name.Local), nil
+}
+}
+}
+
+// rssDocument представляет структуру RSS 2.0 документа
+type rssDocument struct {
+Base    string `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+Channel struct {
+Base  string `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+Title string `xml:"title"`
+// atom:link (rel="self") объявлен раньше link, чтобы не перезаписывать ссылку на сайт
+AtomLinks   []atomLink `xml:"http://www.w3.org/2005/Atom link"`
+Link        string     `xml:"link"`
+Description string     `xml:"description"`
+// itunes:image объявлен раньше image по той же причине
+ITunesImage struct {
+Href string `xml:"href,attr"`
+} `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+Image struct {
+URL string `xml:"url"`
+} `xml:"image"`
+Language  string    `xml:"language"`
+Generator string    `xml:"generator"`
+Items     []rssItem `xml:"item"`
+channelHints
+} `xml:"channel"`
+}
+
+// rssItem представляет элемент RSS 2.0 канала
+type rssItem struct {
+Base string `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+// itunes:title и media:title объявлены раньше title, чтобы не перезаписывать
+// основной заголовок: поле без пространства имен совпадает с элементом из любого
+ITunesTitle string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd title"`
+MediaTitle  string `xml:"http://search.yahoo.com/mrss/ title"`
+Title       string `xml:"title"`
+// atom:link (rel="self") объявлен раньше link по той же причине
+AtomLinks []atomLink `xml:"http://www.w3.org/2005/Atom link"`
+Link      string     `xml:"link"`
+// media:description объявлен раньше description по той же причине
+MediaDescription string     `xml:"http://search.yahoo.com/mrss/ description"`
+Description      markupText `xml:"description"`
+PubDate          string     `xml:"pubDate"`
+GUID             string     `xml:"guid"`
+// itunes:author объявлен раньше author по той же причине
+ITunesAuthor string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+Author       string         `xml:"author"`
+DCCreators   []string       `xml:"http://purl.org/dc/elements/1.1/ creator"`
+Categories   []string       `xml:"category"`
+DCSubjects   []string       `xml:"http://purl.org/dc/elements/1.1/ subject"`
+DCDate       string         `xml:"http://purl.org/dc/elements/1.1/ date"`
+Content      string         `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+Enclosures   []rssEnclosure `xml:"enclosure"`
+itemMedia
+}
+
+// parseRSS разбирает RSS 2.0 документ
+func parseRSS(data []byte, base *neturl.URL) (*domain.ParsedFeed, error) {
+var doc rssDocument
+warning, err := unmarshalXML(data, &doc)
+if err != nil {
+return nil, err
+}
+
+feed := &domain.ParsedFeed{
+Title:       strings.TrimSpace(doc.Channel.Title),
+Link:        strings.TrimSpace(doc.Channel.Link),
+Description: strings.TrimSpace(doc.Channel.Description),
+ImageURL:    strings.TrimSpace(doc.Channel.Image.URL),
+Language:    strings.TrimSpace(doc.Channel.Language),
+Generator:   strings.TrimSpace(doc.Channel.Generator),
+Hints:       doc.Channel.channelHints.toDomain(),
+Warning:     warning,
+}
+if feed.ImageURL == "" {
+feed.ImageURL = strings.TrimSpace(doc.Channel.ITunesImage.Href)
+}
+channelBase := xmlBase(xmlBase(base, doc.Base), doc.Channel.Base)
+prepareFeed(feed, channelBase)
+
+for _, item := range doc.Channel.Items {
+parsed := domain.FeedItem{
+GUID:        strings.TrimSpace(item.GUID),
+Title:       strings.TrimSpace(item.Title),
+Link:        strings.TrimSpace(item.Link),
+Description: item.Description.value(),
+Content:     strings.TrimSpace(item.Content),
+PubDate:     strings.TrimSpace(item.PubDate),
+}
+// Некоторые RSS 2.0 каналы указывают дату только через dc:date
+if parsed.PubDate == "" {
+parsed.PubDate = strings.TrimSpace(item.DCDate)
+}
+// author содержит адрес электронной почты, dc:creator — имя автора
+parsed.Authors = uniqueNames(append([]string{rssAuthorName(item.Author)}, item.DCCreators...))
+if len(parsed.Authors) == 0 {
+parsed.Authors = uniqueNames([]string{item.ITunesAuthor})
+}
+parsed.Categories = uniqueNames(append(item.Categories, item.DCSubjects...))
+for _, enclosure := range item.Enclosures {
+if strings.TrimSpace(enclosure.URL) == "" {
+continue
+}
+parsed.Enclosures = appendEnclosure(parsed.Enclosures, domain.Enclosure{
+URL:      strings.TrimSpace(enclosure.URL),
+MIMEType: strings.TrimSpace(enclosure.Type),
+Length:   parseLength(enclosure.Length),
+})
+}
+item.itemMedia.apply(&parsed)
+itemBase := xmlBase(channelBase, item.Base)
+prepareItem(&parsed, itemBase, itemBase, itemBase)
+feed.Items = append(feed.Items, parsed)
+}
 
-return &rssparsed, nil
+return feed, nil
 }
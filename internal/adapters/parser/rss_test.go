@@ -0,0 +1,38 @@
+package parser
+
+import "testing"
+
+func TestParseRSSNamespacedElements(t *testing.T) {
+data := []byte(`<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/"
+xmlns:atom="http://www.w3.org/2005/Atom" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+<channel><title>t</title><link>https://ex.com/</link>
+<item>
+<title>Real</title>
+<link>https://ex.com/a</link>
+<description>Body</description>
+<media:title>Media</media:title>
+<media:description>Media body</media:description>
+<itunes:title>Episode</itunes:title>
+<atom:link rel="self" href="https://ex.com/a.xml"/>
+</item>
+</channel></rss>`)
+
+feed, err := parseRSS(data, nil)
+if err != nil {
+t.Fatalf("неожиданная ошибка: %v", err)
+}
+if len(feed.Items) != 1 {
+t.Fatalf("ожидался 1 элемент, получено %d", len(feed.Items))
+}
+
+item := feed.Items[0]
+if item.Title != "Real" {
+t.Errorf("заголовок: получено %q", item.Title)
+}
+if item.Link != "https://ex.com/a" {
+t.Errorf("ссылка: получено %q", item.Link)
+}
+if item.DescriptionText != "Body" {
+t.Errorf("описание: получено %q", item.DescriptionText)
+}
+}
@@ -0,0 +1,51 @@
+package parser
+
+import (
+neturl "net/url"
+"rsshub/internal/adapters/markup"
+"rsshub/internal/domain"
+)
+
+// xmlBase возвращает базовый адрес элемента: значение атрибута xml:base,
+// разрешенное относительно базового адреса родителя
+func xmlBase(parent *neturl.URL, value string) *neturl.URL {
+if value == "" {
+return parent
+}
+ref, err := neturl.Parse(markup.ResolveURL(parent, value))
+if err != nil || !ref.IsAbs() {
+return parent
+}
+return ref
+}
+
+// prepareFeed разрешает относительные адреса канала и переводит описание
+// канала в текст: оно выводится только в терминал
+func prepareFeed(feed *domain.ParsedFeed, base *neturl.URL) {
+feed.Link = markup.ResolveURL(base, feed.Link)
+feed.ImageURL = markup.ResolveURL(base, feed.ImageURL)
+feed.IconURL = markup.ResolveURL(base, feed.IconURL)
+feed.Description = markup.PlainText(feed.Description)
+}
+
+// prepareItem разрешает относительные адреса элемента, очищает HTML описания
+// и содержимого от опасной разметки и вычисляет их текстовые версии.
+// Описание и содержимое Atom могут иметь собственный xml:base.
+func prepareItem(item *domain.FeedItem, base, descriptionBase, contentBase *neturl.URL) {
+item.SourceLink = item.Link
+item.Link = markup.ResolveURL(base, item.Link)
+item.Image = markup.ResolveURL(base, item.Image)
+
+// После разрешения адресов разные записи могут указывать на одно вложение
+enclosures := item.Enclosures
+item.Enclosures = nil
+for _, enclosure := range enclosures {
+enclosure.URL = markup.ResolveURL(base, enclosure.URL)
+item.Enclosures = appendEnclosure(item.Enclosures, enclosure)
+}
+
+item.Description = markup.Sanitize(item.Description, descriptionBase)
+item.Content = markup.Sanitize(item.Content, contentBase)
+item.DescriptionText = markup.PlainText(item.Description)
+item.ContentText = markup.PlainText(item.Content)
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+"context"
+"fmt"
+"rsshub/internal/domain"
+)
+
+// RecordDateFailure запоминает элемент канала с нераспознанной датой.
+// Повторная запись того же элемента обновляет значение и время последнего появления.
+func (r *PostgresRepository) RecordDateFailure(ctx context.Context, failure *domain.DateFailure) error {
+_, err := r.db.ExecContext(ctx, `
+        INSERT INTO date_failures (feed_id, guid, title, value)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (feed_id, guid) DO UPDATE
+        SET title = EXCLUDED.title, value = EXCLUDED.value, last_seen_at = NOW()
+    `, failure.FeedID, failure.GUID, failure.Title, failure.Value)
+if err != nil {
+return fmt.Errorf("ошибка сохранения нераспознанной даты: %w", err)
+}
+return nil
+}
+
+// ListDateFailures возвращает последние элементы с нераспознанными датами.
+// Пустое feedName означает все каналы.
+func (r *PostgresRepository) ListDateFailures(ctx context.Context, feedName string, limit int) ([]*domain.DateFailure, error) {
+rows, err := r.db.QueryContext(ctx, `
+        SELECT d.feed_id, d.guid, d.title, d.value, d.first_seen_at, d.last_seen_at, f.name
+        FROM date_failures d
+        JOIN feeds f ON f.id = d.feed_id
+        WHERE $1 = '' OR LOWER(f.name) = LOWER($1)
+        ORDER BY d.last_seen_at DESC
+        LIMIT $2
+    `, feedName, limit)
+if err != nil {
+return nil, fmt.Errorf("ошибка запроса нераспознанных дат: %w", err)
+}
+defer rows.Close()
+
+var failures []*domain.DateFailure
+for rows.Next() {
+failure := &domain.DateFailure{}
+err := rows.Scan(
+&failure.FeedID,
+&failure.GUID,
+&failure.Title,
+&failure.Value,
+&failure.FirstSeenAt,
+&failure.LastSeenAt,
+&failure.FeedName,
+)
+if err != nil {
+return nil, fmt.Errorf("ошибка сканирования нераспознанной даты: %w", err)
+}
+failures = append(failures, failure)
+}
+
+if err := rows.Err(); err != nil {
+return nil, fmt.Errorf("ошибка при итерации по нераспознанным датам: %w", err)
+}
+return failures, nil
+}
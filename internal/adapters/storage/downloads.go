@@ -0,0 +1,163 @@
+package storage
+
+import (
+"context"
+"database/sql"
+"fmt"
+"rsshub/internal/domain"
+)
+
+// downloadColumns перечисляет столбцы загрузки, вложения, статьи и канала
+// в порядке, ожидаемом scanDownload
+const downloadColumns = `d.id, d.enclosure_id, d.created_at, d.updated_at, d.status, d.path,
+d.downloaded, d.size, d.attempts, d.error, e.url, e.mime_type, f.name, a.title, a.published_at, a.episode`
+
+// downloadJoins связывает загрузку с вложением, статьей и каналом
+const downloadJoins = `
+JOIN enclosures e ON e.id = d.enclosure_id
+JOIN articles a ON a.id = e.article_id
+JOIN feeds f ON f.id = a.feed_id`
+
+// failedRetryDelay — минимальная пауза перед повторной загрузкой после ошибки
+const failedRetryDelay = "10 minutes"
+
+// scanDownload считывает загрузку из строки результата запроса
+func scanDownload(row rowScanner) (*domain.Download, error) {
+var download domain.Download
+err := row.Scan(
+&download.ID,
+&download.EnclosureID,
+&download.CreatedAt,
+&download.UpdatedAt,
+&download.Status,
+&download.Path,
+&download.Downloaded,
+&download.Size,
+&download.Attempts,
+&download.Error,
+&download.URL,
+&download.MIMEType,
+&download.FeedName,
+&download.ArticleTitle,
+&download.PublishedAt,
+&download.Episode,
+)
+if err != nil {
+return nil, err
+}
+return &download, nil
+}
+
+// QueueDownloads добавляет в очередь вложения каналов с включенной загрузкой
+func (r *PostgresRepository) QueueDownloads(ctx context.Context) (int, error) {
+result, err := r.db.ExecContext(ctx, `
+        INSERT INTO downloads (enclosure_id)
+        SELECT e.id
+        FROM enclosures e
+        JOIN articles a ON a.id = e.article_id
+        JOIN feeds f ON f.id = a.feed_id
+        WHERE f.download_enclosures
+        ON CONFLICT (enclosure_id) DO NOTHING
+    `)
+if err != nil {
+return 0, fmt.Errorf("ошибка добавления загрузок в очередь: %w", err)
+}
+
+queued, err := result.RowsAffected()
+if err != nil {
+return 0, err
+}
+return int(queued), nil
+}
+
+// ClaimDownloads отмечает до limit ожидающих загрузок как выполняемые и возвращает их.
+// Загрузки с ошибкой повторяются не чаще раза в failedRetryDelay, пока число
+// попыток меньше maxAttempts.
+func (r *PostgresRepository) ClaimDownloads(ctx context.Context, limit int, maxAttempts int) ([]*domain.Download, error) {
+query := `
+        WITH claimed AS (
+            SELECT d.id
+            FROM downloads d` + downloadJoins + `
+            WHERE f.download_enclosures
+              AND (d.status = $1
+                OR (d.status = $2 AND d.attempts < $3 AND d.updated_at < NOW() - INTERVAL '` + failedRetryDelay + `'))
+            ORDER BY d.created_at, d.id
+            LIMIT $4
+            FOR UPDATE OF d SKIP LOCKED
+        )
+        UPDATE downloads d
+        SET status = $5, attempts = d.attempts + 1, error = '', updated_at = NOW()
+        FROM claimed, enclosures e, articles a, feeds f
+        WHERE d.id = claimed.id AND e.id = d.enclosure_id AND a.id = e.article_id AND f.id = a.feed_id
+        RETURNING ` + downloadColumns
+
+rows, err := r.db.QueryContext(ctx, query, domain.DownloadPending, domain.DownloadFailed,
+maxAttempts, limit, domain.DownloadActive)
+if err != nil {
+return nil, fmt.Errorf("ошибка выбора загрузок: %w", err)
+}
+defer rows.Close()
+
+return scanDownloads(rows)
+}
+
+// ResetActiveDownloads возвращает в очередь загрузки, прерванные остановкой процесса
+func (r *PostgresRepository) ResetActiveDownloads(ctx context.Context) error {
+_, err := r.db.ExecContext(ctx, `UPDATE downloads SET status = $1 WHERE status = $2`,
+domain.DownloadPending, domain.DownloadActive)
+if err != nil {
+return fmt.Errorf("ошибка возврата загрузок в очередь: %w", err)
+}
+return nil
+}
+
+// UpdateDownload сохраняет состояние загрузки
+func (r *PostgresRepository) UpdateDownload(ctx context.Context, download *domain.Download) error {
+_, err := r.db.ExecContext(ctx, `
+        UPDATE downloads
+        SET status = $1, path = $2, downloaded = $3, size = $4, error = $5, updated_at = NOW()
+        WHERE id = $6
+    `, download.Status, download.Path, download.Downloaded, download.Size, download.Error, download.ID)
+if err != nil {
+return fmt.Errorf("ошибка обновления загрузки %d: %w", download.ID, err)
+}
+return nil
+}
+
+// ListDownloads возвращает последние загрузки. Пустые feedName и status
+// означают загрузки всех каналов в любом состоянии.
+func (r *PostgresRepository) ListDownloads(ctx context.Context, feedName string, status domain.DownloadStatus, limit int) ([]*domain.Download, error) {
+query := `
+        SELECT ` + downloadColumns + `
+        FROM downloads d` + downloadJoins + `
+        WHERE ($1 = '' OR LOWER(f.name) = LOWER($1))
+          AND ($2 = '' OR d.status = $2)
+        ORDER BY d.updated_at DESC, d.id DESC
+        LIMIT $3
+    `
+
+rows, err := r.db.QueryContext(ctx, query, feedName, string(status), limit)
+if err != nil {
+return nil, fmt.Errorf("ошибка запроса загрузок: %w", err)
+}
+defer rows.Close()
+
+return scanDownloads(rows)
+}
+
+// scanDownloads считывает все загрузки из результата запроса
+func scanDownloads(rows *sql.Rows) ([]*domain.Download, error) {
+var downloads []*domain.Download
+for rows.Next() {
+download, err := scanDownload(rows)
+if err != nil {
+return nil, fmt.Errorf("ошибка сканирования загрузки: %w", err)
+}
+downloads = append(downloads, download)
+}
+
+if err := rows.Err(); err != nil {
+return nil, fmt.Errorf("ошибка при итерации по загрузкам: %w", err)
+}
+return downloads, nil
+}
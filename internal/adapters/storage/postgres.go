@@ -3,6 +3,8 @@ package storage
 import (
 "context"
 "database/sql"
+"encoding/json"
+"errors"
 "fmt"
 "os"
 "path/filepath"
@@ -10,7 +12,7 @@ import (
 "sort"
 "time"
 
-_ "github.com/lib/pq" // Драйвер PostgreSQL
+"github.com/lib/pq" // Драйвер PostgreSQL
 )
 
 // PostgresRepository реализует интерфейсы domain.FeedRepository и domain.ArticleRepository
@@ -152,6 +154,83 @@ fmt.Printf("Миграция %s успешно применена\n", fileName)
 return nil
 }
 
+// feedColumns перечисляет столбцы таблицы feeds в порядке, ожидаемом scanFeed
+const feedColumns = `id, created_at, updated_at, name, url, category, etag, last_modified,
+refresh_interval, next_fetch_at, adaptive_interval, poll_hints, ignore_hints, download_enclosures,
+title, description, site_url, image_url, icon_url, language, generator, date_fallback, parse_warning`
+
+// rowScanner обобщает *sql.Row и *sql.Rows
+type rowScanner interface {
+Scan(dest ...any) error
+}
+
+// scanFeed считывает канал из строки результата запроса
+func scanFeed(row rowScanner) (*domain.Feed, error) {
+var (
+feed             domain.Feed
+refreshInterval  int64
+nextFetchAt      sql.NullTime
+adaptiveInterval int64
+pollHints        string
+)
+err := row.Scan(
+&feed.ID,
+&feed.CreatedAt,
+&feed.UpdatedAt,
+&feed.Name,
+&feed.URL,
+&feed.Category,
+&feed.ETag,
+&feed.LastModified,
+&refreshInterval,
+&nextFetchAt,
+&adaptiveInterval,
+&pollHints,
+&feed.IgnoreHints,
+&feed.DownloadEnclosures,
+&feed.Metadata.Title,
+&feed.Metadata.Description,
+&feed.Metadata.SiteURL,
+&feed.Metadata.ImageURL,
+&feed.Metadata.IconURL,
+&feed.Metadata.Language,
+&feed.Metadata.Generator,
+&feed.DateFallback,
+&feed.ParseWarning,
+)
+if err != nil {
+return nil, err
+}
+
+feed.RefreshInterval = time.Duration(refreshInterval) * time.Second
+feed.AdaptiveInterval = time.Duration(adaptiveInterval) * time.Second
+if err := json.Unmarshal([]byte(pollHints), &feed.Hints); err != nil {
+return nil, fmt.Errorf("ошибка чтения рекомендаций опроса канала %d: %w", feed.ID, err)
+}
+if nextFetchAt.Valid {
+feed.NextFetchAt = nextFetchAt.Time
+}
+return &feed, nil
+}
+
+// scanFeeds считывает все каналы из результата запроса
+func scanFeeds(rows *sql.Rows) ([]*domain.Feed, error) {
+var feeds []*domain.Feed
+for rows.Next() {
+feed, err := scanFeed(rows)
+if err != nil {
+return nil, err
+}
+feeds = append(feeds, feed)
+}
+
+if err := rows.Err(); err != nil {
+return nil, fmt.Errorf("error iterating rows: %w", err)
+}
+
+return feeds, nil
+}
+
 // AddFeed добавляет новый канал в базу данных
 func (r *PostgresRepository) AddFeed(ctx context.Context, feed *domain.Feed) error {
 tx, err := r.db.BeginTx(ctx, nil)
@@ -160,11 +239,18 @@ return err
 }
 
 query := `
-INSERT INTO feeds (created_at, updated_at, name, url)
-VALUES (NOW(), NOW(), $1, $2)
+INSERT INTO feeds (created_at, updated_at, name, url, category, refresh_interval, ignore_hints, download_enclosures,
+    date_fallback)
+VALUES (NOW(), NOW(), $1, $2, $3, $4, $5, $6, $7)
 `
 
-_, err = tx.ExecContext(ctx, query, feed.Name, feed.URL)
+dateFallback := feed.DateFallback
+if dateFallback == "" {
+dateFallback = domain.DateFallbackNow
+}
+
+_, err = tx.ExecContext(ctx, query, feed.Name, feed.URL, feed.Category,
+int64(feed.RefreshInterval/time.Second), feed.IgnoreHints, feed.DownloadEnclosures, dateFallback)
 if err != nil {
 tx.Rollback()
 return err
@@ -173,33 +259,44 @@ return err
 return tx.Commit()
 }
 
+// GetFeedByID возвращает канал по идентификатору
+func (r *PostgresRepository) GetFeedByID(ctx context.Context, id int) (*domain.Feed, error) {
+query := `
+SELECT ` + feedColumns + `
+FROM feeds
+WHERE id = $1
+`
+
+return scanFeed(r.db.QueryRowContext(ctx, query, id))
+}
+
 // GetFeedByName возвращает канал по имени
 func (r *PostgresRepository) GetFeedByName(ctx context.Context, name string) (*domain.Feed, error) {
 query := `
-SELECT id, created_at, updated_at, name, url
+SELECT ` + feedColumns + `
 FROM feeds
 WHERE name = $1
 `
 
-var feed domain.Feed
-err := r.db.QueryRowContext(ctx, query, name).Scan(
-&feed.ID,
-&feed.CreatedAt,
-&feed.UpdatedAt,
-&feed.Name,
-&feed.URL,
-)
-if err != nil {
-return nil, err
+return scanFeed(r.db.QueryRowContext(ctx, query, name))
 }
 
-return &feed, nil
+// GetFeedByURL возвращает канал по URL
+func (r *PostgresRepository) GetFeedByURL(ctx context.Context, url string) (*domain.Feed, error) {
+query := `
+SELECT ` + feedColumns + `
+FROM feeds
+WHERE url = $1
+LIMIT 1
+`
+
+return scanFeed(r.db.QueryRowContext(ctx, query, url))
 }
 
 // ListFeeds возвращает список каналов с ограничением по количеству
 func (r *PostgresRepository) ListFeeds(ctx context.Context, limit int) ([]*domain.Feed, error) {
 query := `
-SELECT id, created_at, updated_at, name, url
+SELECT ` + feedColumns + `
 FROM feeds
 ORDER BY created_at DESC
 LIMIT $1
@@ -210,27 +307,23 @@ return nil, err
 }
 defer rows.Close()
 
-var results []*domain.Feed
-for rows.Next() {
-var feed domain.Feed
-err = rows.Scan(
-&feed.ID,
-&feed.CreatedAt,
-&feed.UpdatedAt,
-&feed.Name,
-&feed.URL,
-)
-if err != nil {
-return nil, err
-}
-results = append(results, &feed)
+return scanFeeds(rows)
 }
 
-if err := rows.Err(); err != nil {
-return nil, fmt.Errorf("error iterating rows: %w", err)
+// ListAllFeeds возвращает все каналы, упорядоченные по категории и имени
+func (r *PostgresRepository) ListAllFeeds(ctx context.Context) ([]*domain.Feed, error) {
+query := `
+SELECT ` + feedColumns + `
+FROM feeds
+ORDER BY category, name
+`
+rows, err := r.db.QueryContext(ctx, query)
+if err != nil {
+return nil, err
 }
+defer rows.Close()
 
-return results, nil
+return scanFeeds(rows)
 }
 
 // DeleteFeed удаляет канал по имени
@@ -255,56 +348,250 @@ return fmt.Errorf("канал с именем '%s' не найден", name)
 return nil
 }
 
-// GetOutdatedFeeds получает каналы, которые давно не обновлялись
-func (r *PostgresRepository) GetOutdatedFeeds(ctx context.Context, count int) ([]*domain.Feed, error) {
+// ClaimOutdatedFeeds захватывает каналы, время следующего обновления которых наступило.
+// Каналы, которые еще ни разу не планировались, обрабатываются первыми.
+// Следующая загрузка захваченных каналов откладывается на lease, поэтому
+// канал, который еще загружается или ждет свободного воркера, не попадет
+// в очередь повторно. После обработки канал перепланируется через ScheduleFeed.
+func (r *PostgresRepository) ClaimOutdatedFeeds(ctx context.Context, count int, lease time.Duration) ([]*domain.Feed, error) {
 query := `
-        SELECT id, created_at, updated_at, name, url
-        FROM feeds
-        ORDER BY updated_at ASC
-        LIMIT $1
+        UPDATE feeds
+        SET next_fetch_at = NOW() + make_interval(secs => $2)
+        WHERE id IN (
+            SELECT id
+            FROM feeds
+            WHERE next_fetch_at IS NULL OR next_fetch_at <= NOW()
+            ORDER BY next_fetch_at ASC NULLS FIRST, updated_at ASC
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING ` + feedColumns + `
     `
 
-rows, err := r.db.QueryContext(ctx, query, count)
+rows, err := r.db.QueryContext(ctx, query, count, lease.Seconds())
 if err != nil {
 return nil, err
 }
 defer rows.Close()
 
-var feeds []*domain.Feed
-for rows.Next() {
-var feed domain.Feed
-err = rows.Scan(&feed.ID, &feed.CreatedAt, &feed.UpdatedAt, &feed.Name, &feed.URL)
+return scanFeeds(rows)
+}
+
+// UpdateFeedTimestamp обновляет время последнего обновления канала
+func (r *PostgresRepository) UpdateFeedTimestamp(ctx context.Context, feedID int) error {
+query := `
+UPDATE feeds SET updated_at = NOW() WHERE id = $1
+`
+_, err := r.db.ExecContext(ctx, query, feedID)
+return err
+}
+
+// UpdateFeed обновляет изменяемые пользователем параметры канала.
+// При смене интервала или режима учета рекомендаций канал перепланируется на ближайший тик.
+// При смене адреса сбрасываются также валидаторы кэша, рекомендации опроса,
+// адаптивный интервал и сведения о канале: они относятся к прежнему документу,
+// и условный запрос с чужими валидаторами мог бы получить 304 Not Modified.
+func (r *PostgresRepository) UpdateFeed(ctx context.Context, feed *domain.Feed) error {
+query := `
+UPDATE feeds
+SET url = $1,
+    category = $2,
+    next_fetch_at = CASE
+        WHEN url <> $1 OR refresh_interval <> $3 OR ignore_hints <> $4 THEN NULL
+        ELSE next_fetch_at
+    END,
+    etag = CASE WHEN url <> $1 THEN '' ELSE etag END,
+    last_modified = CASE WHEN url <> $1 THEN '' ELSE last_modified END,
+    poll_hints = CASE WHEN url <> $1 THEN '{}' ELSE poll_hints END,
+    adaptive_interval = CASE WHEN url <> $1 THEN 0 ELSE adaptive_interval END,
+    title = CASE WHEN url <> $1 THEN '' ELSE title END,
+    description = CASE WHEN url <> $1 THEN '' ELSE description END,
+    site_url = CASE WHEN url <> $1 THEN '' ELSE site_url END,
+    image_url = CASE WHEN url <> $1 THEN '' ELSE image_url END,
+    icon_url = CASE WHEN url <> $1 THEN '' ELSE icon_url END,
+    language = CASE WHEN url <> $1 THEN '' ELSE language END,
+    generator = CASE WHEN url <> $1 THEN '' ELSE generator END,
+    parse_warning = CASE WHEN url <> $1 THEN '' ELSE parse_warning END,
+    refresh_interval = $3,
+    ignore_hints = $4,
+    download_enclosures = $5,
+    date_fallback = $6
+WHERE id = $7
+`
+result, err := r.db.ExecContext(ctx, query,
+feed.URL, feed.Category, int64(feed.RefreshInterval/time.Second), feed.IgnoreHints, feed.DownloadEnclosures,
+feed.DateFallback, feed.ID)
 if err != nil {
-return nil, err
+return err
 }
-feeds = append(feeds, &feed)
+
+rowsAffected, err := result.RowsAffected()
+if err != nil {
+return err
 }
 
-if err = rows.Err(); err != nil {
-return nil, err
+if rowsAffected == 0 {
+return fmt.Errorf("канал с именем '%s' не найден", feed.Name)
 }
 
-return feeds, nil
+return nil
 }
 
-// UpdateFeedTimestamp обновляет время последнего обновления канала
-func (r *PostgresRepository) UpdateFeedTimestamp(ctx context.Context, feedID int) error {
+// ScheduleFeed откладывает следующую загрузку канала на delay от текущего момента.
+// Время вычисляется на стороне БД, чтобы не зависеть от часового пояса процесса.
+func (r *PostgresRepository) ScheduleFeed(ctx context.Context, feedID int, delay time.Duration) error {
 query := `
-UPDATE feeds SET updated_at = NOW() WHERE id = $1
+UPDATE feeds SET next_fetch_at = NOW() + make_interval(secs => $1) WHERE id = $2
 `
-_, err := r.db.ExecContext(ctx, query, feedID)
+_, err := r.db.ExecContext(ctx, query, delay.Seconds(), feedID)
+return err
+}
+
+// UpdateAdaptiveInterval сохраняет вычисленный адаптивный интервал обновления канала
+func (r *PostgresRepository) UpdateAdaptiveInterval(ctx context.Context, feedID int, interval time.Duration) error {
+query := `
+UPDATE feeds SET adaptive_interval = $1 WHERE id = $2
+`
+_, err := r.db.ExecContext(ctx, query, int64(interval/time.Second), feedID)
+return err
+}
+
+// GetPublishingInterval возвращает средний промежуток между публикациями
+// последних sample статей канала. Если статей меньше двух, возвращается 0.
+func (r *PostgresRepository) GetPublishingInterval(ctx context.Context, feedID int, sample int) (time.Duration, error) {
+query := `
+        SELECT COALESCE(EXTRACT(EPOCH FROM MAX(published_at) - MIN(published_at)) / NULLIF(COUNT(*) - 1, 0), 0)
+        FROM (
+            SELECT published_at
+            FROM articles
+            WHERE feed_id = $1
+            ORDER BY published_at DESC
+            LIMIT $2
+        ) recent
+    `
+
+var seconds float64
+err := r.db.QueryRowContext(ctx, query, feedID, sample).Scan(&seconds)
+if err != nil {
+return 0, err
+}
+
+return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// UpdatePollingHints сохраняет рекомендации издателя о частоте опроса канала
+func (r *PostgresRepository) UpdatePollingHints(ctx context.Context, feedID int, hints domain.PollingHints) error {
+data, err := json.Marshal(hints)
+if err != nil {
+return err
+}
+
+query := `
+UPDATE feeds SET poll_hints = $1 WHERE id = $2
+`
+_, err = r.db.ExecContext(ctx, query, string(data), feedID)
+return err
+}
+
+// UpdateFeedCacheValidators сохраняет ETag и Last-Modified последнего ответа сервера
+func (r *PostgresRepository) UpdateFeedCacheValidators(ctx context.Context, feedID int, validators domain.CacheValidators) error {
+query := `
+UPDATE feeds SET etag = $1, last_modified = $2 WHERE id = $3
+`
+_, err := r.db.ExecContext(ctx, query, validators.ETag, validators.LastModified, feedID)
+return err
+}
+
+// UpdateFeedMetadata сохраняет сведения о канале из последнего загруженного документа
+func (r *PostgresRepository) UpdateFeedMetadata(ctx context.Context, feedID int, metadata domain.FeedMetadata) error {
+query := `
+UPDATE feeds
+SET title = $1, description = $2, site_url = $3, image_url = $4, icon_url = $5, language = $6, generator = $7
+WHERE id = $8
+`
+_, err := r.db.ExecContext(ctx, query, metadata.Title, metadata.Description, metadata.SiteURL,
+metadata.ImageURL, metadata.IconURL, metadata.Language, metadata.Generator, feedID)
 return err
 }
 
-// AddArticle добавляет новую статью в базу данных
-func (r *PostgresRepository) AddArticle(ctx context.Context, article *domain.Article) error {
+// UpdateFeedParseWarning сохраняет предупреждение о разборе последнего загруженного документа
+func (r *PostgresRepository) UpdateFeedParseWarning(ctx context.Context, feedID int, warning string) error {
+_, err := r.db.ExecContext(ctx, `UPDATE feeds SET parse_warning = $1 WHERE id = $2`, warning, feedID)
+return err
+}
+
+// RekeyLegacyArticles заменяет идентификатор статей, отмеченных миграцией
+// как сохраненные до появления guid, на key(ссылка статьи). Если статья
+// с таким идентификатором уже есть (ссылка менялась параметрами отслеживания),
+// идентификатор не меняется. Отметка снимается в обоих случаях.
+func (r *PostgresRepository) RekeyLegacyArticles(ctx context.Context, key func(link string) string) (int, error) {
+type legacyArticle struct {
+id     int
+feedID int
+link   string
+}
+
+rows, err := r.db.QueryContext(ctx, `SELECT id, feed_id, link FROM articles WHERE legacy_guid`)
+if err != nil {
+return 0, err
+}
+var articles []legacyArticle
+for rows.Next() {
+var article legacyArticle
+if err := rows.Scan(&article.id, &article.feedID, &article.link); err != nil {
+rows.Close()
+return 0, err
+}
+articles = append(articles, article)
+}
+rows.Close()
+if err := rows.Err(); err != nil {
+return 0, err
+}
+
+updated := 0
+for _, article := range articles {
+guid := key(article.link)
+if guid == "" {
+guid = article.link
+}
+result, err := r.db.ExecContext(ctx, `
+        UPDATE articles SET guid = $1, legacy_guid = FALSE
+        WHERE id = $2 AND guid <> $1
+          AND NOT EXISTS (SELECT 1 FROM articles WHERE feed_id = $3 AND guid = $1)
+    `, guid, article.id, article.feedID)
+if err != nil {
+return updated, fmt.Errorf("ошибка обновления идентификатора статьи %d: %w", article.id, err)
+}
+if n, _ := result.RowsAffected(); n > 0 {
+updated++
+continue
+}
+_, err = r.db.ExecContext(ctx, `UPDATE articles SET legacy_guid = FALSE WHERE id = $1`, article.id)
+if err != nil {
+return updated, fmt.Errorf("ошибка обновления идентификатора статьи %d: %w", article.id, err)
+}
+}
+return updated, nil
+}
+
+// SaveArticle добавляет статью или обновляет существующую, если изменилось
+// ее содержимое. Статья идентифицируется парой (feed_id, guid), изменение
+// определяется по content_hash. Перед обновлением прежняя версия статьи
+// сохраняется в article_revisions.
+//
+// Пустой content_hash означает, что хеш статьи еще не вычислен: такая статья
+// обновляется без создания версии. Миграции, которые добавляют входящие в хеш
+// поля или меняют их обработку, сбрасывают хеши, чтобы существующие статьи
+// были дополнены при следующей загрузке, а не получили ложную версию.
+func (r *PostgresRepository) SaveArticle(ctx context.Context, article *domain.Article) (domain.ArticleChange, error) {
 // Проверка входных данных
 if article == nil {
-return fmt.Errorf("статья не может быть nil")
+return domain.ArticleUnchanged, fmt.Errorf("статья не может быть nil")
 }
 
-if article.Title == "" || article.Link == "" || article.FeedID == 0 {
-return fmt.Errorf("необходимо указать title, link и feed_id")
+if article.Title == "" || article.Link == "" || article.GUID == "" || article.FeedID == 0 {
+return domain.ArticleUnchanged, fmt.Errorf("необходимо указать title, link, guid и feed_id")
 }
 
 // Используем текущее время, если время публикации не указано
@@ -313,58 +600,271 @@ if publishedAt.IsZero() {
 publishedAt = time.Now()
 }
 
+tx, err := r.db.BeginTx(ctx, nil)
+if err != nil {
+return domain.ArticleUnchanged, fmt.Errorf("ошибка начала транзакции: %w", err)
+}
+defer tx.Rollback()
+
+// Статьи, сохраненные до появления guid, идентифицируются ссылкой:
+// нормализованной (см. RekeyLegacyArticles) или еще не обновленной.
+// Присваиваем им настоящий guid, чтобы они не продублировались.
+_, err = tx.ExecContext(ctx, `
+        UPDATE articles SET guid = $1, legacy_guid = FALSE
+        WHERE feed_id = $2 AND guid <> $1
+          AND (guid = $3 OR (guid = link AND link = $4))
+          AND NOT EXISTS (SELECT 1 FROM articles WHERE feed_id = $2 AND guid = $1)
+    `, article.GUID, article.FeedID, article.LinkGUID, article.Link)
+if err != nil {
+return domain.ArticleUnchanged, fmt.Errorf("ошибка обновления идентификатора статьи: %w", err)
+}
+
+// Статьи без guid, сохраненные до разрешения относительных ссылок,
+// идентифицируются относительной ссылкой
+if article.LegacyGUID != "" && article.LegacyGUID != article.GUID {
+_, err = tx.ExecContext(ctx, `
+        UPDATE articles SET guid = $1
+        WHERE feed_id = $2 AND guid = $3
+          AND NOT EXISTS (SELECT 1 FROM articles WHERE feed_id = $2 AND guid = $1)
+    `, article.GUID, article.FeedID, article.LegacyGUID)
+if err != nil {
+return domain.ArticleUnchanged, fmt.Errorf("ошибка обновления идентификатора статьи: %w", err)
+}
+}
+
+// Блокируем существующую статью до конца транзакции
+var (
+existingID   int
+existingHash string
+)
+err = tx.QueryRowContext(ctx, `
+        SELECT id, content_hash FROM articles
+        WHERE feed_id = $1 AND guid = $2
+        FOR UPDATE
+    `, article.FeedID, article.GUID).Scan(&existingID, &existingHash)
+
+change := domain.ArticleUnchanged
+switch {
+case errors.Is(err, sql.ErrNoRows):
 // SQL-запрос для вставки статьи
 query := `
         INSERT INTO articles (
-            created_at, updated_at, title, link, published_at, description, feed_id
+            created_at, updated_at, title, link, published_at, description, content, feed_id, guid, content_hash,
+            image_url, episode, season, description_text, content_text
         ) VALUES (
-            NOW(), NOW(), $1, $2, $3, $4, $5
-        ) ON CONFLICT (link) DO NOTHING
+            NOW(), NOW(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+        ) ON CONFLICT (feed_id, guid) DO NOTHING
+        RETURNING id
     `
-
-// Выполнение запроса
-result, err := r.db.ExecContext(
+err = tx.QueryRowContext(
 ctx,
 query,
 article.Title,
 article.Link,
 publishedAt,
 article.Description,
+article.Content,
 article.FeedID,
-)
+article.GUID,
+article.ContentHash,
+article.ImageURL,
+article.Episode,
+article.Season,
+article.DescriptionText,
+article.ContentText,
+).Scan(&article.ID)
+if errors.Is(err, sql.ErrNoRows) {
+// Статью одновременно добавил другой воркер, это не ошибка
+return domain.ArticleUnchanged, nil
+}
+if err != nil {
+return domain.ArticleUnchanged, fmt.Errorf("ошибка добавления статьи: %w", err)
+}
+change = domain.ArticleInserted
+
+case err != nil:
+return domain.ArticleUnchanged, fmt.Errorf("ошибка поиска статьи: %w", err)
+
+case existingHash == article.ContentHash:
+article.ID = existingID
+return domain.ArticleUnchanged, nil
+
+case existingHash == "":
+// Хеш еще не вычислен: дополняем статью без создания версии
+article.ID = existingID
+_, err = tx.ExecContext(ctx, `
+        UPDATE articles
+        SET content_hash = $1, description = $2, content = $3, image_url = $4, episode = $5, season = $6,
+            description_text = $7, content_text = $8, link = $9
+        WHERE id = $10
+    `, article.ContentHash, article.Description, article.Content, article.ImageURL, article.Episode, article.Season,
+article.DescriptionText, article.ContentText, article.Link, existingID)
+if err != nil {
+return domain.ArticleUnchanged, fmt.Errorf("ошибка сохранения хеша статьи: %w", err)
+}
 
+default:
+article.ID = existingID
+
+// Сохраняем прежнюю версию статьи
+_, err = tx.ExecContext(ctx, `
+        INSERT INTO article_revisions (article_id, title, link, published_at, description, content, content_hash)
+        SELECT id, title, link, published_at, description, content, content_hash
+        FROM articles WHERE id = $1
+    `, existingID)
 if err != nil {
-return fmt.Errorf("ошибка добавления статьи: %w", err)
+return domain.ArticleUnchanged, fmt.Errorf("ошибка сохранения версии статьи: %w", err)
 }
 
-// Проверка, была ли статья действительно добавлена
-rowsAffected, err := result.RowsAffected()
+_, err = tx.ExecContext(ctx, `
+        UPDATE articles
+        SET updated_at = NOW(), title = $1, link = $2, published_at = $3, description = $4,
+            content = $5, content_hash = $6, image_url = $7, episode = $8, season = $9,
+            description_text = $10, content_text = $11
+        WHERE id = $12
+    `, article.Title, article.Link, publishedAt, article.Description, article.Content, article.ContentHash,
+article.ImageURL, article.Episode, article.Season, article.DescriptionText, article.ContentText, existingID)
 if err != nil {
-return fmt.Errorf("ошибка получения количества затронутых строк: %w", err)
+return domain.ArticleUnchanged, fmt.Errorf("ошибка обновления статьи: %w", err)
+}
+change = domain.ArticleUpdated
 }
 
-if rowsAffected == 0 {
-// Статья уже существует, но это не ошибка
+// Вложения, авторы и категории синхронизируются при любом изменении статьи
+if err := saveEnclosures(ctx, tx, article.ID, article.Enclosures); err != nil {
+return domain.ArticleUnchanged, err
+}
+if err := saveTerms(ctx, tx, authorTerms, article.ID, article.Authors); err != nil {
+return domain.ArticleUnchanged, err
+}
+if err := saveTerms(ctx, tx, categoryTerms, article.ID, article.Categories); err != nil {
+return domain.ArticleUnchanged, err
+}
+
+if err := tx.Commit(); err != nil {
+return domain.ArticleUnchanged, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+}
+
+return change, nil
+}
+
+// saveEnclosures приводит вложения статьи к переданному списку. Существующие
+// записи обновляются на месте, чтобы не терять ссылки на них.
+func saveEnclosures(ctx context.Context, tx *sql.Tx, articleID int, enclosures []domain.Enclosure) error {
+urls := make([]string, 0, len(enclosures))
+for i := range enclosures {
+enclosure := &enclosures[i]
+err := tx.QueryRowContext(ctx, `
+        INSERT INTO enclosures (article_id, url, mime_type, length, duration)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (article_id, url) DO UPDATE
+        SET mime_type = EXCLUDED.mime_type, length = EXCLUDED.length, duration = EXCLUDED.duration
+        RETURNING id
+    `, articleID, enclosure.URL, enclosure.MIMEType, enclosure.Length,
+int64(enclosure.Duration/time.Second)).Scan(&enclosure.ID)
+if err != nil {
+return fmt.Errorf("ошибка сохранения вложения %s: %w", enclosure.URL, err)
+}
+enclosure.ArticleID = articleID
+urls = append(urls, enclosure.URL)
+}
+
+_, err := tx.ExecContext(ctx, `DELETE FROM enclosures WHERE article_id = $1 AND NOT (url = ANY($2))`,
+articleID, pq.Array(urls))
+if err != nil {
+return fmt.Errorf("ошибка удаления вложений статьи: %w", err)
+}
+return nil
+}
+
+// loadArticleDetails загружает вложения, авторов и категории переданных статей
+func (r *PostgresRepository) loadArticleDetails(ctx context.Context, articles []*domain.Article) error {
+if err := r.loadEnclosures(ctx, articles); err != nil {
+return err
+}
+if err := r.loadTerms(ctx, authorTerms, articles, func(article *domain.Article, name string) {
+article.Authors = append(article.Authors, name)
+}); err != nil {
+return err
+}
+return r.loadTerms(ctx, categoryTerms, articles, func(article *domain.Article, name string) {
+article.Categories = append(article.Categories, name)
+})
+}
+
+// loadEnclosures загружает вложения переданных статей
+func (r *PostgresRepository) loadEnclosures(ctx context.Context, articles []*domain.Article) error {
+if len(articles) == 0 {
 return nil
 }
 
+byID := make(map[int]*domain.Article, len(articles))
+ids := make([]int64, 0, len(articles))
+for _, article := range articles {
+byID[article.ID] = article
+ids = append(ids, int64(article.ID))
+}
+
+rows, err := r.db.QueryContext(ctx, `
+        SELECT id, article_id, url, mime_type, length, duration
+        FROM enclosures
+        WHERE article_id = ANY($1)
+        ORDER BY id
+    `, pq.Array(ids))
+if err != nil {
+return fmt.Errorf("ошибка запроса вложений: %w", err)
+}
+defer rows.Close()
+
+for rows.Next() {
+var (
+enclosure domain.Enclosure
+duration  int64
+)
+err := rows.Scan(
+&enclosure.ID,
+&enclosure.ArticleID,
+&enclosure.URL,
+&enclosure.MIMEType,
+&enclosure.Length,
+&duration,
+)
+if err != nil {
+return fmt.Errorf("ошибка сканирования вложения: %w", err)
+}
+enclosure.Duration = time.Duration(duration) * time.Second
+if article, ok := byID[enclosure.ArticleID]; ok {
+article.Enclosures = append(article.Enclosures, enclosure)
+}
+}
+
+if err := rows.Err(); err != nil {
+return fmt.Errorf("ошибка при итерации по вложениям: %w", err)
+}
 return nil
 }
 
-// GetArticlesByFeed возвращает статьи канала
-func (r *PostgresRepository) GetArticlesByFeed(ctx context.Context, feedName string, limit int) ([]*domain.Article, error) {
-// Запрос для получения статей канала без учета регистра
+// GetArticles возвращает последние статьи, удовлетворяющие фильтру.
+// Канал, автор и категория сравниваются без учета регистра.
+func (r *PostgresRepository) GetArticles(ctx context.Context, filter domain.ArticleFilter) ([]*domain.Article, error) {
 query := `
-        SELECT a.id, a.created_at, a.updated_at, a.title, a.link, a.published_at, a.description, a.feed_id
+        SELECT a.id, a.created_at, a.updated_at, a.title, a.link, a.published_at, a.description, a.feed_id, a.guid, a.content_hash,
+            a.image_url, a.episode, a.season, a.description_text
         FROM articles a
         JOIN feeds f ON a.feed_id = f.id
-        WHERE LOWER(f.name) = LOWER($1)
+        WHERE ($1 = '' OR LOWER(f.name) = LOWER($1))
+          AND ($2 = '' OR EXISTS (
+              SELECT 1 FROM article_authors aa JOIN authors au ON au.id = aa.author_id
+              WHERE aa.article_id = a.id AND LOWER(au.name) = LOWER($2)))
+          AND ($3 = '' OR EXISTS (
+              SELECT 1 FROM article_categories ac JOIN categories c ON c.id = ac.category_id
+              WHERE ac.article_id = a.id AND LOWER(c.name) = LOWER($3)))
         ORDER BY a.published_at DESC
-        LIMIT $2
+        LIMIT $4
     `
 
 // Выполнение запроса
-rows, err := r.db.QueryContext(ctx, query, feedName, limit)
+rows, err := r.db.QueryContext(ctx, query, filter.FeedName, filter.Author, filter.Category, filter.Limit)
 if err != nil {
 return nil, fmt.Errorf("ошибка запроса статей: %w", err)
 }
@@ -383,6 +883,12 @@ err := rows.Scan(
 &article.PublishedAt,
 &article.Description,
 &article.FeedID,
+&article.GUID,
+&article.ContentHash,
+&article.ImageURL,
+&article.Episode,
+&article.Season,
+&article.DescriptionText,
 )
 if err != nil {
 return nil, fmt.Errorf("ошибка сканирования статьи: %w", err)
@@ -395,5 +901,93 @@ if err := rows.Err(); err != nil {
 return nil, fmt.Errorf("ошибка при итерации по статьям: %w", err)
 }
 
+if err := r.loadArticleDetails(ctx, articles); err != nil {
+return nil, err
+}
+
 return articles, nil
 }
+
+// GetArticleByID возвращает статью вместе с полным текстом
+func (r *PostgresRepository) GetArticleByID(ctx context.Context, id int) (*domain.Article, error) {
+query := `
+        SELECT id, created_at, updated_at, title, link, published_at, COALESCE(description, ''),
+            content, feed_id, guid, content_hash, image_url, episode, season, description_text, content_text
+        FROM articles
+        WHERE id = $1
+    `
+
+article := &domain.Article{}
+err := r.db.QueryRowContext(ctx, query, id).Scan(
+&article.ID,
+&article.CreatedAt,
+&article.UpdatedAt,
+&article.Title,
+&article.Link,
+&article.PublishedAt,
+&article.Description,
+&article.Content,
+&article.FeedID,
+&article.GUID,
+&article.ContentHash,
+&article.ImageURL,
+&article.Episode,
+&article.Season,
+&article.DescriptionText,
+&article.ContentText,
+)
+if errors.Is(err, sql.ErrNoRows) {
+return nil, fmt.Errorf("статья с ID %d не найдена", id)
+}
+if err != nil {
+return nil, fmt.Errorf("ошибка получения статьи: %w", err)
+}
+
+if err := r.loadArticleDetails(ctx, []*domain.Article{article}); err != nil {
+return nil, err
+}
+
+return article, nil
+}
+
+// GetArticleRevisions возвращает прежние версии статьи, начиная с последней
+func (r *PostgresRepository) GetArticleRevisions(ctx context.Context, articleID int) ([]*domain.ArticleRevision, error) {
+query := `
+        SELECT id, article_id, created_at, title, link, published_at, COALESCE(description, ''), content, content_hash
+        FROM article_revisions
+        WHERE article_id = $1
+        ORDER BY created_at DESC, id DESC
+    `
+
+rows, err := r.db.QueryContext(ctx, query, articleID)
+if err != nil {
+return nil, fmt.Errorf("ошибка запроса версий статьи: %w", err)
+}
+defer rows.Close()
+
+var revisions []*domain.ArticleRevision
+for rows.Next() {
+revision := &domain.ArticleRevision{}
+err := rows.Scan(
+&revision.ID,
+&revision.ArticleID,
+&revision.CreatedAt,
+&revision.Title,
+&revision.Link,
+&revision.PublishedAt,
+&revision.Description,
+&revision.Content,
+&revision.ContentHash,
+)
+if err != nil {
+return nil, fmt.Errorf("ошибка сканирования версии статьи: %w", err)
+}
+revisions = append(revisions, revision)
+}
+
+if err := rows.Err(); err != nil {
+return nil, fmt.Errorf("ошибка при итерации по версиям статьи: %w", err)
+}
+
+return revisions, nil
+}
@@ -0,0 +1,145 @@
+package storage
+
+import (
+"context"
+"fmt"
+"os"
+"rsshub/internal/domain"
+"strings"
+"testing"
+"time"
+)
+
+// newTestRepository подключается к тестовой базе из RSSHUB_TEST_DATABASE_URL
+// и применяет миграции. Без переменной окружения тест пропускается.
+func newTestRepository(t *testing.T) *PostgresRepository {
+t.Helper()
+connStr := os.Getenv("RSSHUB_TEST_DATABASE_URL")
+if connStr == "" {
+t.Skip("RSSHUB_TEST_DATABASE_URL не задана")
+}
+
+repo, err := NewPostgresRepository(connStr)
+if err != nil {
+t.Fatalf("ошибка подключения к БД: %v", err)
+}
+t.Cleanup(func() { repo.Close() })
+
+if err := repo.RunMigrations("../../../migrations"); err != nil {
+t.Fatalf("ошибка миграций: %v", err)
+}
+return repo
+}
+
+// newTestFeed добавляет канал с уникальным именем и удаляет его после теста
+func newTestFeed(t *testing.T, repo *PostgresRepository) *domain.Feed {
+t.Helper()
+ctx := context.Background()
+name := fmt.Sprintf("test-%s-%d", strings.ToLower(t.Name()), time.Now().UnixNano())
+
+if err := repo.AddFeed(ctx, &domain.Feed{Name: name, URL: "https://example.com/" + name}); err != nil {
+t.Fatalf("ошибка добавления канала: %v", err)
+}
+feed, err := repo.GetFeedByName(ctx, name)
+if err != nil {
+t.Fatalf("ошибка получения канала: %v", err)
+}
+t.Cleanup(func() { repo.DeleteFeed(context.Background(), name) })
+return feed
+}
+
+// stripQuery заменяет нормализацию ссылок агрегатора: удаляет параметры
+func stripQuery(link string) string {
+link, _, _ = strings.Cut(link, "?")
+return link
+}
+
+func TestRekeyLegacyArticles(t *testing.T) {
+repo := newTestRepository(t)
+feed := newTestFeed(t, repo)
+ctx := context.Background()
+
+// Статья сохранена до появления guid: идентификатором стала ссылка
+// с параметрами отслеживания. Хеш сброшен миграцией.
+legacyLink := "https://example.com/post?utm_source=old"
+_, err := repo.DB().ExecContext(ctx, `
+        INSERT INTO articles (created_at, updated_at, title, link, published_at, description, feed_id, guid,
+            content_hash, legacy_guid)
+        VALUES (NOW(), NOW(), 'Post', $1, NOW(), '', $2, $1, '', TRUE)
+    `, legacyLink, feed.ID)
+if err != nil {
+t.Fatalf("ошибка добавления статьи: %v", err)
+}
+
+count, err := repo.RekeyLegacyArticles(ctx, stripQuery)
+if err != nil {
+t.Fatalf("RekeyLegacyArticles: %v", err)
+}
+if count < 1 {
+t.Fatalf("ожидалось обновление статьи, обновлено %d", count)
+}
+
+// Элемент получен с настоящим guid и другими параметрами отслеживания
+newLink := "https://example.com/post?utm_source=new"
+article := &domain.Article{
+Title:       "Post",
+Link:        newLink,
+FeedID:      feed.ID,
+GUID:        "urn:post:1",
+LinkGUID:    stripQuery(newLink),
+ContentHash: "hash",
+PublishedAt: time.Now(),
+}
+change, err := repo.SaveArticle(ctx, article)
+if err != nil {
+t.Fatalf("SaveArticle: %v", err)
+}
+if change == domain.ArticleInserted {
+t.Fatalf("статья добавлена повторно вместо обновления идентификатора")
+}
+
+articles, err := repo.GetArticles(ctx, domain.ArticleFilter{FeedName: feed.Name, Limit: 10})
+if err != nil {
+t.Fatalf("GetArticles: %v", err)
+}
+if len(articles) != 1 {
+t.Fatalf("ожидалась 1 статья, получено %d", len(articles))
+}
+if articles[0].GUID != "urn:post:1" || articles[0].Link != newLink {
+t.Fatalf("guid %q, ссылка %q", articles[0].GUID, articles[0].Link)
+}
+}
+
+func TestRekeyLegacyArticlesConflict(t *testing.T) {
+repo := newTestRepository(t)
+feed := newTestFeed(t, repo)
+ctx := context.Background()
+
+// Две копии одной статьи, различающиеся только параметрами отслеживания
+for _, link := range []string{"https://example.com/dup?utm_source=a", "https://example.com/dup?utm_source=b"} {
+_, err := repo.DB().ExecContext(ctx, `
+        INSERT INTO articles (created_at, updated_at, title, link, published_at, description, feed_id, guid,
+            content_hash, legacy_guid)
+        VALUES (NOW(), NOW(), 'Dup', $1, NOW(), '', $2, $1, '', TRUE)
+    `, link, feed.ID)
+if err != nil {
+t.Fatalf("ошибка добавления статьи: %v", err)
+}
+}
+
+if _, err := repo.RekeyLegacyArticles(ctx, stripQuery); err != nil {
+t.Fatalf("RekeyLegacyArticles: %v", err)
+}
+
+var rekeyed, marked int
+err := repo.DB().QueryRowContext(ctx, `
+        SELECT COUNT(*) FILTER (WHERE guid = $2), COUNT(*) FILTER (WHERE legacy_guid)
+        FROM articles WHERE feed_id = $1
+    `, feed.ID, "https://example.com/dup").Scan(&rekeyed, &marked)
+if err != nil {
+t.Fatalf("ошибка проверки статей: %v", err)
+}
+if rekeyed != 1 || marked != 0 {
+t.Fatalf("получен новый идентификатор у %d статей, отмечено %d", rekeyed, marked)
+}
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+"context"
+"database/sql"
+"fmt"
+"rsshub/internal/domain"
+"sort"
+"strings"
+
+"github.com/lib/pq"
+)
+
+// termTable описывает справочник имен (авторов или категорий) и таблицу его связей со статьями
+type termTable struct {
+table  string
+links  string
+column string
+}
+
+var (
+authorTerms   = termTable{table: "authors", links: "article_authors", column: "author_id"}
+categoryTerms = termTable{table: "categories", links: "article_categories", column: "category_id"}
+)
+
+// saveTerms заменяет связи статьи со справочником переданными именами,
+// добавляя в справочник отсутствующие имена
+func saveTerms(ctx context.Context, tx *sql.Tx, terms termTable, articleID int, names []string) error {
+_, err := tx.ExecContext(ctx, `DELETE FROM `+terms.links+` WHERE article_id = $1`, articleID)
+if err != nil {
+return fmt.Errorf("ошибка удаления связей %s: %w", terms.table, err)
+}
+
+// Имена блокируются в одном порядке во всех транзакциях, иначе воркеры,
+// сохраняющие статьи с теми же авторами, могли бы взаимно заблокироваться
+order := make([]int, len(names))
+for i := range order {
+order[i] = i
+}
+sort.Slice(order, func(i, j int) bool {
+return strings.ToLower(names[order[i]]) < strings.ToLower(names[order[j]])
+})
+
+for _, position := range order {
+name := names[position]
+// DO UPDATE нужен, чтобы RETURNING вернул идентификатор существующего имени
+var termID int
+err := tx.QueryRowContext(ctx, `
+        INSERT INTO `+terms.table+` (name) VALUES ($1)
+        ON CONFLICT ((LOWER(name))) DO UPDATE SET name = `+terms.table+`.name
+        RETURNING id
+    `, name).Scan(&termID)
+if err != nil {
+return fmt.Errorf("ошибка сохранения %s %q: %w", terms.table, name, err)
+}
+
+_, err = tx.ExecContext(ctx, `
+        INSERT INTO `+terms.links+` (article_id, `+terms.column+`, position)
+        VALUES ($1, $2, $3)
+        ON CONFLICT DO NOTHING
+    `, articleID, termID, position)
+if err != nil {
+return fmt.Errorf("ошибка сохранения связи %s: %w", terms.table, err)
+}
+}
+return nil
+}
+
+// loadTerms загружает имена из справочника для переданных статей в порядке из канала
+func (r *PostgresRepository) loadTerms(ctx context.Context, terms termTable, articles []*domain.Article, add func(*domain.Article, string)) error {
+if len(articles) == 0 {
+return nil
+}
+
+byID := make(map[int]*domain.Article, len(articles))
+ids := make([]int64, 0, len(articles))
+for _, article := range articles {
+byID[article.ID] = article
+ids = append(ids, int64(article.ID))
+}
+
+rows, err := r.db.QueryContext(ctx, `
+        SELECT l.article_id, t.name
+        FROM `+terms.links+` l
+        JOIN `+terms.table+` t ON t.id = l.`+terms.column+`
+        WHERE l.article_id = ANY($1)
+        ORDER BY l.article_id, l.position
+    `, pq.Array(ids))
+if err != nil {
+return fmt.Errorf("ошибка запроса %s: %w", terms.table, err)
+}
+defer rows.Close()
+
+for rows.Next() {
+var (
+articleID int
+name      string
+)
+if err := rows.Scan(&articleID, &name); err != nil {
+return fmt.Errorf("ошибка сканирования %s: %w", terms.table, err)
+}
+if article, ok := byID[articleID]; ok {
+add(article, name)
+}
+}
+
+if err := rows.Err(); err != nil {
+return fmt.Errorf("ошибка при итерации по %s: %w", terms.table, err)
+}
+return nil
+}
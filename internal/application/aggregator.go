@@ -2,6 +2,7 @@ package application
 
 import (
 "context"
+"errors"
 "fmt"
 "rsshub/internal/domain"
 "sync"
@@ -15,9 +16,16 @@ parser      domain.RSSParser
 interval    time.Duration
 workerCount int
 
+// Границы адаптивного интервала; адаптивное планирование выключено, если adaptive == false
+adaptive    bool
+minInterval time.Duration
+maxInterval time.Duration
+
 ticker  *time.Ticker
 jobCh   chan int
 done    chan struct{}
+ctx     context.Context
+cancel  context.CancelFunc
 running bool
 mu      sync.Mutex
 }
@@ -42,8 +50,13 @@ if a.running {
 return fmt.Errorf("агрегатор уже запущен")
 }
 
-// Запускаем тикер
-a.ticker = time.NewTicker(a.interval)
+// Собственный контекст позволяет Stop прервать загрузки, которые уже выполняются
+ctx, a.cancel = context.WithCancel(ctx)
+a.ctx = ctx
+
+// Запускаем тикер. Он лишь проверяет, каким каналам пора обновиться,
+// сам интервал обновления учитывается при планировании каждого канала.
+a.ticker = time.NewTicker(tickInterval(a.interval))
 a.running = true
 a.jobCh = make(chan int, a.workerCount)
 
@@ -54,6 +67,8 @@ go a.worker(ctx, i)
 
 // Запускаем основной цикл обработки
 go func() {
+a.rekeyLegacyArticles(ctx)
+
 // Сразу запускаем первую обработку
 a.processFeeds(ctx)
 
@@ -86,8 +101,9 @@ if a.ticker != nil {
 a.ticker.Stop()
 }
 
-// Сигнализируем о завершении
+// Сигнализируем о завершении и прерываем выполняющиеся запросы
 close(a.done)
+a.cancel()
 
 // Закрываем канал задач после того, как все горутины завершатся
 time.Sleep(100 * time.Millisecond)
@@ -106,7 +122,7 @@ a.interval = d
 
 // Если агрегатор запущен, обновляем тикер
 if a.running && a.ticker != nil {
-a.ticker.Reset(d)
+a.ticker.Reset(tickInterval(d))
 }
 }
 
@@ -129,7 +145,7 @@ return nil
 if workers > a.workerCount {
 // Запускаем дополнительных воркеров
 for i := a.workerCount; i < workers; i++ {
-go a.worker(context.Background(), i)
+go a.worker(a.ctx, i)
 }
 }
 
@@ -166,7 +182,7 @@ return
 // processFeeds получает и обрабатывает каналы
 func (a *RSSAggregator) processFeeds(ctx context.Context) {
 // Получаем список каналов для обновления
-feeds, err := a.repo.GetOutdatedFeeds(ctx, a.workerCount)
+feeds, err := a.repo.ClaimOutdatedFeeds(ctx, a.workerCount, claimLease)
 if err != nil {
 fmt.Printf("Ошибка получения устаревших каналов: %v\n", err)
 return
@@ -190,10 +206,7 @@ return
 // processFeed обрабатывает один канал
 func (a *RSSAggregator) processFeed(ctx context.Context, workerID, feedID int) {
 // Получаем информацию о канале
-var feed domain.Feed
-err := a.repo.DB().QueryRowContext(ctx,
-"SELECT id, name, url FROM feeds WHERE id = $1", feedID).
-Scan(&feed.ID, &feed.Name, &feed.URL)
+feed, err := a.repo.GetFeedByID(ctx, feedID)
 if err != nil {
 fmt.Printf("Воркер %d: ошибка получения информации о канале %d: %v\n",
 workerID, feedID, err)
@@ -202,41 +215,125 @@ return
 
 fmt.Printf("Воркер %d: обработка канала %s (%s)\n", workerID, feed.Name, feed.URL)
 
-// Получаем RSS
-rssFeed, err := a.parser.ParseFeed(feed.URL)
+// Следующая загрузка планируется при любом исходе, иначе канал
+// с ошибкой оставался бы первым в очереди при каждом тике
+var outcome fetchOutcome
+defer func() {
+a.scheduleNext(ctx, workerID, feed, outcome)
+}()
+
+// Получаем RSS, передавая валидаторы предыдущего ответа для условного GET
+rssFeed, err := a.parser.ParseFeed(ctx, feed.URL, domain.CacheValidators{
+ETag:         feed.ETag,
+LastModified: feed.LastModified,
+})
+if errors.Is(err, domain.ErrNotModified) {
+// Канал получен, но не изменился: статьи повторно не обрабатываем
+outcome.fetched = true
+err = a.repo.UpdateFeedTimestamp(ctx, feedID)
+if err != nil {
+fmt.Printf("Воркер %d: ошибка обновления времени канала %s: %v\n",
+workerID, feed.Name, err)
+}
+fmt.Printf("Воркер %d: канал %s не изменился\n", workerID, feed.Name)
+return
+}
+var limitErr *domain.FeedLimitError
+if errors.As(err, &limitErr) {
+// Документ слишком велик или подозрителен: повторная загрузка
+// произойдет по расписанию, как и при любой другой ошибке
+fmt.Printf("Воркер %d: канал %s отклонен: %v\n", workerID, feed.Name, err)
+return
+}
 if err != nil {
 fmt.Printf("Воркер %d: ошибка парсинга канала %s: %v\n",
 workerID, feed.Name, err)
 return
 }
 
+outcome.fetched = true
+
+// Сохраняем рекомендации издателя о частоте опроса; при ответе 304
+// планировщик использует рекомендации, сохраненные ранее
+feed.Hints = rssFeed.Hints
+err = a.repo.UpdatePollingHints(ctx, feedID, rssFeed.Hints)
+if err != nil {
+fmt.Printf("Воркер %d: ошибка сохранения рекомендаций опроса канала %s: %v\n",
+workerID, feed.Name, err)
+}
+
+// Обновляем сведения о канале
+err = a.repo.UpdateFeedMetadata(ctx, feedID, domain.FeedMetadata{
+Title:       rssFeed.Title,
+Description: rssFeed.Description,
+SiteURL:     rssFeed.Link,
+ImageURL:    rssFeed.ImageURL,
+IconURL:     rssFeed.IconURL,
+Language:    rssFeed.Language,
+Generator:   rssFeed.Generator,
+})
+if err != nil {
+fmt.Printf("Воркер %d: ошибка сохранения сведений о канале %s: %v\n",
+workerID, feed.Name, err)
+}
+
+// Предупреждение сбрасывается, когда канал исправит документ
+if rssFeed.Warning != "" {
+fmt.Printf("Воркер %d: канал %s: %s\n", workerID, feed.Name, rssFeed.Warning)
+}
+err = a.repo.UpdateFeedParseWarning(ctx, feedID, rssFeed.Warning)
+if err != nil {
+fmt.Printf("Воркер %d: ошибка сохранения предупреждения канала %s: %v\n",
+workerID, feed.Name, err)
+}
+
 // Обрабатываем статьи
-for _, item := range rssFeed.Channel.Items {
+updated, skipped := 0, 0
+for _, item := range rssFeed.Items {
+guid := articleGUID(item)
+
 // Парсим дату публикации
-pubDate := time.Now()
-if item.PubDate != "" {
-parsed, err := a.parsePubDate(item.PubDate)
-if err == nil {
-pubDate = parsed
-}
+pubDate, ok := a.publicationDate(ctx, workerID, feed, guid, item)
+if !ok {
+skipped++
+continue
 }
 
 // Создаем объект статьи
 article := &domain.Article{
-Title:       item.Title,
-Link:        item.Link,
-PublishedAt: pubDate,
-Description: item.Description,
-FeedID:      feedID,
-}
-
-// Добавляем статью в БД
-err = a.repo.(domain.ArticleRepository).AddArticle(ctx, article)
+Title:           item.Title,
+Link:            item.Link,
+PublishedAt:     pubDate,
+Description:     item.Description,
+Content:         item.Content,
+DescriptionText: item.DescriptionText,
+ContentText:     item.ContentText,
+FeedID:          feedID,
+GUID:            guid,
+LinkGUID:        normalizeLink(item.Link),
+LegacyGUID:      legacyArticleGUID(item),
+ContentHash:     contentHash(item),
+ImageURL:        item.Image,
+Episode:         item.Episode,
+Season:          item.Season,
+Enclosures:      item.Enclosures,
+Authors:         item.Authors,
+Categories:      item.Categories,
+}
+
+// Добавляем статью в БД или обновляем измененную
+change, err := a.repo.(domain.ArticleRepository).SaveArticle(ctx, article)
 if err != nil {
 fmt.Printf("Воркер %d: ошибка добавления статьи %s: %v\n",
 workerID, item.Title, err)
 continue
 }
+switch change {
+case domain.ArticleInserted:
+outcome.newItems++
+case domain.ArticleUpdated:
+updated++
+}
 }
 
 // Обновляем время последнего обновления канала
@@ -246,26 +343,55 @@ fmt.Printf("Воркер %d: ошибка обновления времени к
 workerID, feed.Name, err)
 }
 
-fmt.Printf("Воркер %d: канал %s обработан, найдено %d статей\n",
-workerID, feed.Name, len(rssFeed.Channel.Items))
+// Сохраняем валидаторы для следующего условного запроса
+err = a.repo.UpdateFeedCacheValidators(ctx, feedID, rssFeed.Validators)
+if err != nil {
+fmt.Printf("Воркер %d: ошибка сохранения валидаторов канала %s: %v\n",
+workerID, feed.Name, err)
+}
+
+fmt.Printf("Воркер %d: канал %s обработан, найдено %d статей, новых %d, изменено %d\n",
+workerID, feed.Name, len(rssFeed.Items), outcome.newItems, updated)
+if skipped > 0 {
+fmt.Printf("Воркер %d: канал %s: пропущено %d статей без распознанной даты\n",
+workerID, feed.Name, skipped)
+}
+}
+
+// publicationDate возвращает дату публикации элемента. Если дата не указана
+// или не распознана, используется дата обновления, а затем запасной вариант
+// канала. Нераспознанные даты сохраняются для анализа. ok == false означает,
+// что элемент нужно пропустить.
+func (a *RSSAggregator) publicationDate(ctx context.Context, workerID int, feed *domain.Feed, guid string, item domain.FeedItem) (time.Time, bool) {
+pubDate, err := parseDate(item.PubDate)
+if err == nil {
+return pubDate, true
 }
 
-// parsePubDate парсит дату публикации
-func (a *RSSAggregator) parsePubDate(pubDate string) (time.Time, error) {
-formats := []string{
-time.RFC1123,
-time.RFC1123Z,
-time.RFC822,
-time.RFC822Z,
-"Mon, 02 Jan 2006 15:04:05 -0700",
-"Mon, 2 Jan 2006 15:04:05 -0700",
+if item.PubDate != "" {
+err := a.repo.(domain.ArticleRepository).RecordDateFailure(ctx, &domain.DateFailure{
+FeedID: feed.ID,
+GUID:   guid,
+Title:  item.Title,
+Value:  item.PubDate,
+})
+if err != nil {
+fmt.Printf("Воркер %d: %v\n", workerID, err)
+}
 }
 
-for _, format := range formats {
-if t, err := time.Parse(format, pubDate); err == nil {
-return t, nil
+if item.Updated != "" && item.Updated != item.PubDate {
+if updated, err := parseDate(item.Updated); err == nil {
+return updated, true
 }
 }
 
-return time.Time{}, fmt.Errorf("не удалось распарсить дату: %s", pubDate)
+switch feed.DateFallback {
+case domain.DateFallbackSkip:
+return time.Time{}, false
+case domain.DateFallbackOldest:
+return time.Unix(0, 0).UTC(), true
+default:
+return time.Now(), true
+}
 }
@@ -0,0 +1,238 @@
+package application
+
+import (
+"fmt"
+"regexp"
+"strconv"
+"strings"
+"time"
+)
+
+// dateLayouts перечисляет форматы дат после нормализации normalizeDate:
+// день недели удален, месяцы приведены к английским сокращениям,
+// названия часовых поясов заменены смещением вида -0700
+var dateLayouts = []string{
+// ISO 8601 / RFC 3339 / W3CDTF
+time.RFC3339,
+"2006-01-02T15:04:05Z0700",
+"2006-01-02T15:04:05",
+"2006-01-02T15:04Z07:00",
+"2006-01-02T15:04Z0700",
+"2006-01-02T15:04",
+"2006-01-02 15:04:05Z07:00",
+"2006-01-02 15:04:05 -0700",
+"2006-01-02 15:04:05",
+"2006-01-02 15:04 -0700",
+"2006-01-02 15:04",
+"20060102T150405Z0700",
+"20060102T150405",
+"2006-01-02",
+"2006-01",
+"2006",
+
+// RFC 822 / RFC 1123 и их распространенные искажения
+"2 Jan 2006 15:04:05 -0700",
+"2 Jan 2006 15:04 -0700",
+"2 Jan 2006 15:04:05 MST",
+"2 Jan 2006 15:04:05",
+"2 Jan 2006 15:04",
+"2 Jan 06 15:04:05 -0700",
+"2 Jan 06 15:04 -0700",
+"2 Jan 06 15:04:05 MST",
+"2 Jan 06 15:04:05",
+"2 Jan 2006",
+"2 Jan 06",
+
+// Американский порядок, ANSI C и Unix date
+"Jan 2 2006 15:04:05 -0700",
+"Jan 2 2006 15:04:05",
+"Jan 2 2006 15:04",
+"Jan 2 2006 3:04 PM",
+"Jan 2 2006 3:04PM",
+"Jan 2 2006",
+"Jan 2 15:04:05 -0700 2006",
+"Jan 2 15:04:05 MST 2006",
+"Jan 2 15:04:05 2006",
+
+// Числовые форматы
+"2.1.2006 15:04:05",
+"2.1.2006 15:04",
+"2.1.2006",
+"2006/1/2 15:04:05",
+"2006/1/2 15:04",
+"2006/1/2",
+}
+
+// dateMonths сопоставляет названия месяцев на разных языках с английскими сокращениями
+var dateMonths = map[string]string{}
+
+// dateWeekdays содержит названия и сокращения дней недели на разных языках
+var dateWeekdays = map[string]bool{}
+
+func init() {
+months := [12][]string{
+{"january", "jan", "янв", "январь", "января", "januar", "janvier", "janv", "enero", "ene"},
+{"february", "feb", "фев", "февр", "февраль", "февраля", "februar", "février", "févr", "fév", "febrero"},
+{"march", "mar", "мар", "март", "марта", "märz", "mär", "mrz", "mars", "marzo"},
+{"april", "apr", "апр", "апрель", "апреля", "avril", "avr", "abril", "abr"},
+{"may", "май", "мая", "mai", "mayo"},
+{"june", "jun", "июн", "июнь", "июня", "juni", "juin", "junio"},
+{"july", "jul", "июл", "июль", "июля", "juli", "juillet", "juil", "julio"},
+{"august", "aug", "авг", "август", "августа", "août", "aout", "agosto", "ago"},
+{"september", "sep", "sept", "сен", "сент", "сентябрь", "сентября", "septembre", "septiembre", "setiembre", "set"},
+{"october", "oct", "окт", "октябрь", "октября", "oktober", "okt", "octobre", "octubre"},
+{"november", "nov", "ноя", "нояб", "ноябрь", "ноября", "novembre", "noviembre"},
+{"december", "dec", "дек", "декабрь", "декабря", "dezember", "dez", "décembre", "déc", "diciembre", "dic"},
+}
+for i, names := range months {
+short := time.Month(i + 1).String()[:3]
+for _, name := range names {
+dateMonths[name] = short
+}
+}
+
+for _, name := range []string{
+"mon", "tue", "tues", "wed", "thu", "thur", "thurs", "fri", "sat", "sun",
+"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday",
+"пн", "вт", "ср", "чт", "пт", "сб", "вс",
+"понедельник", "вторник", "среда", "четверг", "пятница", "суббота", "воскресенье",
+"mo", "di", "mi", "do", "fr", "sa", "so",
+"montag", "dienstag", "mittwoch", "donnerstag", "freitag", "samstag", "sonntag",
+"lun", "mar", "mer", "jeu", "ven", "sam", "dim",
+"lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi", "dimanche",
+"mié", "mie", "jue", "vie", "sáb", "dom",
+"lunes", "martes", "miércoles", "jueves", "viernes", "sábado", "domingo",
+} {
+dateWeekdays[name] = true
+}
+}
+
+// dateZones сопоставляет распространенные названия часовых поясов со смещением.
+// time.Parse не знает смещений большинства сокращений и считает их UTC.
+var dateZones = map[string]string{
+"z": "+0000", "ut": "+0000", "utc": "+0000", "gmt": "+0000",
+"est": "-0500", "edt": "-0400", "cst": "-0600", "cdt": "-0500",
+"mst": "-0700", "mdt": "-0600", "pst": "-0800", "pdt": "-0700",
+"akst": "-0900", "akdt": "-0800", "hst": "-1000",
+"ast": "-0400", "adt": "-0300", "nst": "-0330", "ndt": "-0230",
+"wet": "+0000", "west": "+0100", "bst": "+0100",
+"cet": "+0100", "cest": "+0200", "met": "+0100", "mest": "+0200", "mez": "+0100", "mesz": "+0200",
+"eet": "+0200", "eest": "+0300", "msk": "+0300", "msd": "+0400",
+"ist": "+0530", "sgt": "+0800", "hkt": "+0800", "awst": "+0800",
+"jst": "+0900", "kst": "+0900", "acst": "+0930", "acdt": "+1030",
+"aest": "+1000", "aedt": "+1100", "nzst": "+1200", "nzdt": "+1300",
+}
+
+// dateFillerWords удаляются из даты ("12 января 2024 г. в 10:00", "Jan 2, 2006 at 10:00")
+var dateFillerWords = map[string]bool{"г": true, "в": true, "at": true, "um": true, "à": true, "de": true}
+
+var (
+dateCommentPattern = regexp.MustCompile(`\s*\([^)]*\)\s*$`)
+dateOrdinalPattern = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th|\.)$`)
+dateOffsetPattern  = regexp.MustCompile(`^(?:gmt|utc)?([+-])(\d{1,2}):?(\d{2})?$`)
+dateUnixPattern    = regexp.MustCompile(`^\d{9,10}$|^\d{12,13}$`)
+)
+
+// minUnixDate и maxUnixDate ограничивают даты, записанные Unix-временем
+var (
+minUnixDate = time.Date(1995, 1, 1, 0, 0, 0, 0, time.UTC)
+maxUnixDate = time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// parseDate разбирает дату публикации в одном из распространенных в каналах
+// форматов. Дата без часового пояса считается датой в UTC.
+func parseDate(value string) (time.Time, error) {
+normalized := normalizeDate(value)
+if normalized == "" {
+return time.Time{}, fmt.Errorf("дата не указана")
+}
+
+// Unix-время в секундах или миллисекундах. Число вне правдоподобного
+// диапазона скорее является идентификатором, чем датой.
+if dateUnixPattern.MatchString(normalized) {
+number, _ := strconv.ParseInt(normalized, 10, 64)
+t := time.Unix(number, 0).UTC()
+if len(normalized) > 10 {
+t = time.UnixMilli(number).UTC()
+}
+if t.Before(minUnixDate) || t.After(maxUnixDate) {
+return time.Time{}, fmt.Errorf("не удалось распарсить дату: %s", value)
+}
+return t, nil
+}
+
+for _, layout := range dateLayouts {
+if t, err := time.Parse(layout, normalized); err == nil {
+return t, nil
+}
+}
+
+return time.Time{}, fmt.Errorf("не удалось распарсить дату: %s", value)
+}
+
+// normalizeDate приводит дату к виду, который разбирается одним из dateLayouts
+func normalizeDate(value string) string {
+value = strings.TrimSpace(value)
+// Комментарий после часового пояса: "+0000 (UTC)"
+value = dateCommentPattern.ReplaceAllString(value, "")
+// Запятые не несут смысла и встречаются в разных местах
+value = strings.ReplaceAll(value, ",", " ")
+
+tokens := strings.Fields(value)
+
+months := 0
+for _, token := range tokens {
+if dateMonths[strings.TrimSuffix(strings.ToLower(token), ".")] != "" {
+months++
+}
+}
+
+result := make([]string, 0, len(tokens))
+for i, token := range tokens {
+lower := strings.ToLower(token)
+bare := strings.TrimSuffix(lower, ".")
+
+// День недели стоит первым. Сокращение "mar" (mardi, martes) совпадает
+// с мартом, поэтому оно удаляется, только если в дате есть другой месяц.
+if i == 0 && dateWeekdays[bare] && (dateMonths[bare] == "" || months > 1) {
+continue
+}
+if dateFillerWords[bare] {
+continue
+}
+if month, ok := dateMonths[bare]; ok {
+result = append(result, month)
+continue
+}
+// Порядковые числительные: "1st", "12." (немецкий порядок "12. März")
+if match := dateOrdinalPattern.FindStringSubmatch(lower); match != nil {
+result = append(result, match[1])
+continue
+}
+// Часовой пояс ISO 8601 внутри токена ("2006-01-02T15:04:05+03:00") не трогаем
+if i > 0 {
+if zone, ok := dateZones[lower]; ok {
+result = append(result, zone)
+continue
+}
+if match := dateOffsetPattern.FindStringSubmatch(lower); match != nil && (match[3] != "" || strings.HasPrefix(lower, "gmt") || strings.HasPrefix(lower, "utc")) {
+result = append(result, dateOffset(match[1], match[2], match[3]))
+continue
+}
+}
+result = append(result, token)
+}
+
+return strings.Join(result, " ")
+}
+
+// dateOffset формирует смещение вида -0700 из знака, часов и минут
+func dateOffset(sign, hours, minutes string) string {
+if len(hours) == 1 {
+hours = "0" + hours
+}
+if minutes == "" {
+minutes = "00"
+}
+return sign + hours + minutes
+}
@@ -0,0 +1,88 @@
+package application
+
+import (
+"testing"
+"time"
+)
+
+func TestParseDate(t *testing.T) {
+msk := time.FixedZone("", 3*60*60)
+tests := []struct {
+name  string
+value string
+want  time.Time
+}{
+// RFC 822 / RFC 1123
+{"RFC 1123", "Tue, 12 Mar 2024 10:00:00 +0000", time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)},
+{"RFC 822 без дня недели", "12 Mar 2024 10:00:00 +0000", time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)},
+{"RFC 822 без секунд", "Tue, 12 Mar 2024 10:00 +0300", time.Date(2024, 3, 12, 7, 0, 0, 0, time.UTC)},
+{"двузначный год", "Tue, 12 Mar 24 10:00:00 +0000", time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)},
+{"полное название дня и месяца", "Tuesday, 12 March 2024 10:00:00 GMT", time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)},
+{"однозначный день", "Fri, 1 Mar 2024 10:00:00 +0000", time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+
+// Часовые пояса
+{"EST", "Tue, 12 Mar 2024 10:00:00 EST", time.Date(2024, 3, 12, 15, 0, 0, 0, time.UTC)},
+{"MSK", "Tue, 12 Mar 2024 10:00:00 MSK", time.Date(2024, 3, 12, 7, 0, 0, 0, time.UTC)},
+{"GMT+3", "Tue, 12 Mar 2024 10:00:00 GMT+3", time.Date(2024, 3, 12, 7, 0, 0, 0, time.UTC)},
+{"UTC-05:30", "12 Mar 2024 10:00:00 UTC-05:30", time.Date(2024, 3, 12, 15, 30, 0, 0, time.UTC)},
+{"комментарий после пояса", "Tue, 12 Mar 2024 10:00:00 +0000 (UTC)", time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)},
+
+// ISO 8601
+{"RFC 3339", "2024-03-12T10:00:00+03:00", time.Date(2024, 3, 12, 10, 0, 0, 0, msk)},
+{"RFC 3339 с долями секунды", "2024-03-12T10:00:00.123Z", time.Date(2024, 3, 12, 10, 0, 0, 123e6, time.UTC)},
+{"без часового пояса", "2024-03-12 10:00:00", time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)},
+{"только дата", "2024-03-12", time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC)},
+
+// Американский порядок
+{"американский порядок", "March 12th, 2024 at 10:00 AM", time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)},
+{"ANSI C", "Tue Mar 12 10:00:00 2024", time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)},
+
+// Другие языки
+{"русский", "12 марта 2024 г. в 10:00", time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)},
+{"русский с днем недели", "Вт, 12 мар 2024 10:00:00 +0300", time.Date(2024, 3, 12, 7, 0, 0, 0, time.UTC)},
+{"немецкий", "Di, 12. März 2024 10:00:00 +0100", time.Date(2024, 3, 12, 9, 0, 0, 0, time.UTC)},
+{"немецкий без дня недели", "12. Mär. 2024", time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC)},
+{"французский", "mardi 12 mars 2024 10:00", time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)},
+{"французский mar — вторник", "mar., 12 mars 2024 10:00:00 +0100", time.Date(2024, 3, 12, 9, 0, 0, 0, time.UTC)},
+{"испанский", "martes, 12 de marzo de 2024 10:00", time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)},
+{"испанский mar — вторник", "mar, 12 abr 2024 10:00:00 +0200", time.Date(2024, 4, 12, 8, 0, 0, 0, time.UTC)},
+{"mar — март", "Mar 12 2024 10:00:00", time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)},
+
+// Числовые форматы и Unix-время
+{"через точку", "12.03.2024 10:00", time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)},
+{"через косую черту", "2024/03/12", time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC)},
+{"Unix-время в секундах", "1710237600", time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)},
+{"Unix-время в миллисекундах", "1710237600123", time.Date(2024, 3, 12, 10, 0, 0, 123e6, time.UTC)},
+}
+
+for _, tt := range tests {
+t.Run(tt.name, func(t *testing.T) {
+got, err := parseDate(tt.value)
+if err != nil {
+t.Fatalf("parseDate(%q): %v", tt.value, err)
+}
+if !got.Equal(tt.want) {
+t.Errorf("parseDate(%q) = %v, ожидалось %v", tt.value, got, tt.want)
+}
+})
+}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+for _, value := range []string{
+"",
+"   ",
+"вчера",
+"not a date",
+"32 Mar 2024",
+"2024-13-01",
+// Числа вне правдоподобного диапазона Unix-времени
+"12345678901",
+"100000000",
+"9999999999999",
+} {
+if got, err := parseDate(value); err == nil {
+t.Errorf("parseDate(%q) = %v, ожидалась ошибка", value, got)
+}
+}
+}
@@ -0,0 +1,314 @@
+package application
+
+import (
+"context"
+"errors"
+"fmt"
+"mime"
+"os"
+"path"
+"path/filepath"
+"rsshub/internal/domain"
+"strconv"
+"strings"
+"sync"
+"time"
+)
+
+const (
+// downloadPollInterval задает период проверки очереди загрузок
+downloadPollInterval = 30 * time.Second
+// downloadProgressInterval ограничивает частоту сохранения хода загрузки в БД
+downloadProgressInterval = 5 * time.Second
+// maxDownloadAttempts ограничивает количество попыток загрузки одного файла
+maxDownloadAttempts = 3
+// maxNameRunes ограничивает длину подставляемых в шаблон значений
+maxNameRunes = 100
+)
+
+// DefaultDownloadTemplate — шаблон имени файла по умолчанию
+const DefaultDownloadTemplate = "{feed}/{date} {title}{ext}"
+
+// DownloadConfig описывает параметры загрузки вложений
+type DownloadConfig struct {
+// Dir — каталог, в который сохраняются файлы
+Dir string
+// Template задает путь файла относительно Dir. Поддерживаются
+// {feed}, {title}, {date}, {episode}, {id} и {ext}.
+Template string
+// MaxSize ограничивает размер файла в байтах; 0 — без ограничения
+MaxSize int64
+// Workers — количество одновременных загрузок
+Workers int
+}
+
+// EnclosureDownloader загружает вложения каналов, для которых включена загрузка.
+// Использует собственный пул воркеров, чтобы загрузка больших файлов не
+// задерживала обновление каналов.
+type EnclosureDownloader struct {
+repo    domain.DownloadRepository
+fetcher domain.EnclosureFetcher
+cfg     DownloadConfig
+
+wake    chan struct{}
+done    chan struct{}
+wg      sync.WaitGroup
+cancel  context.CancelFunc
+running bool
+mu      sync.Mutex
+}
+
+// NewEnclosureDownloader создает новый экземпляр EnclosureDownloader
+func NewEnclosureDownloader(repo domain.DownloadRepository, fetcher domain.EnclosureFetcher, cfg DownloadConfig) (*EnclosureDownloader, error) {
+if cfg.Dir == "" {
+return nil, fmt.Errorf("необходимо указать каталог для загрузок")
+}
+if cfg.Workers <= 0 {
+return nil, fmt.Errorf("количество загрузок должно быть положительным")
+}
+if cfg.MaxSize < 0 {
+return nil, fmt.Errorf("максимальный размер файла не может быть отрицательным")
+}
+if cfg.Template == "" {
+cfg.Template = DefaultDownloadTemplate
+}
+
+return &EnclosureDownloader{
+repo:    repo,
+fetcher: fetcher,
+cfg:     cfg,
+wake:    make(chan struct{}, cfg.Workers),
+done:    make(chan struct{}),
+}, nil
+}
+
+// Start запускает загрузку вложений
+func (d *EnclosureDownloader) Start(ctx context.Context) error {
+d.mu.Lock()
+defer d.mu.Unlock()
+
+if d.running {
+return fmt.Errorf("загрузчик уже запущен")
+}
+
+// Загрузки, прерванные прошлой остановкой, продолжатся с места остановки
+if err := d.repo.ResetActiveDownloads(ctx); err != nil {
+return err
+}
+
+ctx, d.cancel = context.WithCancel(ctx)
+d.running = true
+
+for i := 0; i < d.cfg.Workers; i++ {
+d.wg.Add(1)
+go d.worker(ctx, i)
+}
+
+d.wg.Add(1)
+go func() {
+defer d.wg.Done()
+
+ticker := time.NewTicker(downloadPollInterval)
+defer ticker.Stop()
+
+for {
+d.queue(ctx)
+
+select {
+case <-ticker.C:
+case <-d.done:
+return
+case <-ctx.Done():
+return
+}
+}
+}()
+
+return nil
+}
+
+// Stop останавливает загрузчик, прерывая выполняющиеся загрузки.
+// Незавершенные файлы будут загружены с места остановки после перезапуска.
+func (d *EnclosureDownloader) Stop() error {
+d.mu.Lock()
+defer d.mu.Unlock()
+
+if !d.running {
+return nil
+}
+
+close(d.done)
+d.cancel()
+d.wg.Wait()
+
+d.running = false
+return nil
+}
+
+// queue добавляет новые вложения в очередь и будит воркеров
+func (d *EnclosureDownloader) queue(ctx context.Context) {
+queued, err := d.repo.QueueDownloads(ctx)
+if err != nil {
+fmt.Printf("Ошибка обновления очереди загрузок: %v\n", err)
+return
+}
+if queued > 0 {
+fmt.Printf("В очередь загрузок добавлено %d файлов\n", queued)
+}
+
+// Воркеры проверяют очередь и при каждом тике: загрузки с ошибкой
+// повторяются спустя некоторое время
+for i := 0; i < d.cfg.Workers; i++ {
+select {
+case d.wake <- struct{}{}:
+default:
+}
+}
+}
+
+// worker выполняет загрузки, пока очередь не опустеет, и ждет следующего тика
+func (d *EnclosureDownloader) worker(ctx context.Context, id int) {
+defer d.wg.Done()
+
+for {
+select {
+case <-d.wake:
+case <-d.done:
+return
+case <-ctx.Done():
+return
+}
+
+for {
+downloads, err := d.repo.ClaimDownloads(ctx, 1, maxDownloadAttempts)
+if err != nil {
+if ctx.Err() == nil {
+fmt.Printf("Загрузчик %d: ошибка получения загрузки: %v\n", id, err)
+}
+break
+}
+if len(downloads) == 0 {
+break
+}
+d.download(ctx, id, downloads[0])
+}
+}
+}
+
+// download загружает один файл и сохраняет результат
+func (d *EnclosureDownloader) download(ctx context.Context, workerID int, download *domain.Download) {
+if download.Path == "" {
+download.Path = d.filePath(download)
+}
+
+fmt.Printf("Загрузчик %d: загрузка %s в %s\n", workerID, download.URL, download.Path)
+
+// Ход загрузки сохраняется не чаще раза в downloadProgressInterval
+lastSaved := time.Now()
+err := d.fetcher.Fetch(ctx, download.URL, download.Path, d.cfg.MaxSize, func(downloaded, size int64) {
+download.Downloaded = downloaded
+download.Size = size
+if time.Since(lastSaved) < downloadProgressInterval {
+return
+}
+lastSaved = time.Now()
+if err := d.repo.UpdateDownload(ctx, download); err != nil {
+fmt.Printf("Загрузчик %d: %v\n", workerID, err)
+}
+})
+
+switch {
+case err == nil:
+download.Status = domain.DownloadDone
+download.Error = ""
+fmt.Printf("Загрузчик %d: файл %s загружен\n", workerID, download.Path)
+case ctx.Err() != nil:
+// Загрузчик остановлен: загрузка продолжится после перезапуска
+download.Status = domain.DownloadPending
+case errors.Is(err, domain.ErrDownloadTooLarge):
+download.Status = domain.DownloadSkipped
+download.Error = err.Error()
+fmt.Printf("Загрузчик %d: файл %s пропущен: %v\n", workerID, download.URL, err)
+default:
+download.Status = domain.DownloadFailed
+download.Error = err.Error()
+fmt.Printf("Загрузчик %d: ошибка загрузки %s: %v\n", workerID, download.URL, err)
+}
+
+// Контекст может быть уже отменен, а результат нужно сохранить
+saveCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+defer cancel()
+if err := d.repo.UpdateDownload(saveCtx, download); err != nil {
+fmt.Printf("Загрузчик %d: %v\n", workerID, err)
+}
+}
+
+// filePath формирует путь файла по шаблону. Если файл с таким именем уже
+// существует, к имени добавляется идентификатор загрузки.
+func (d *EnclosureDownloader) filePath(download *domain.Download) string {
+ext := enclosureExtension(download.URL, download.MIMEType)
+
+episode := ""
+if download.Episode > 0 {
+episode = strconv.Itoa(download.Episode)
+}
+
+name := strings.NewReplacer(
+"{feed}", sanitizeFileName(download.FeedName),
+"{title}", sanitizeFileName(download.ArticleTitle),
+"{date}", download.PublishedAt.Format("2006-01-02"),
+"{episode}", episode,
+"{id}", strconv.Itoa(download.ID),
+"{ext}", ext,
+).Replace(d.cfg.Template)
+
+filePath := filepath.Join(d.cfg.Dir, filepath.FromSlash(name))
+
+if _, err := os.Stat(filePath); err == nil {
+filePath = strings.TrimSuffix(filePath, ext) + "-" + strconv.Itoa(download.ID) + ext
+}
+return filePath
+}
+
+// enclosureExtension определяет расширение файла по адресу или MIME-типу
+func enclosureExtension(rawURL, mimeType string) string {
+urlPath := rawURL
+if i := strings.IndexAny(urlPath, "?#"); i >= 0 {
+urlPath = urlPath[:i]
+}
+if ext := path.Ext(urlPath); ext != "" && len(ext) <= 6 && !strings.ContainsAny(ext, "/:") {
+return strings.ToLower(ext)
+}
+
+if mimeType != "" {
+if extensions, err := mime.ExtensionsByType(mimeType); err == nil && len(extensions) > 0 {
+return extensions[0]
+}
+}
+return ".bin"
+}
+
+// sanitizeFileName заменяет символы, недопустимые в именах файлов, и ограничивает длину
+func sanitizeFileName(value string) string {
+value = strings.Map(func(r rune) rune {
+switch {
+case r < 0x20, r == 0x7f:
+return -1
+case strings.ContainsRune(`/\:*?"<>|`, r):
+return '_'
+}
+return r
+}, value)
+
+value = strings.Join(strings.Fields(value), " ")
+if runes := []rune(value); len(runes) > maxNameRunes {
+value = string(runes[:maxNameRunes])
+}
+
+// Имена "." и ".." и имена с точкой в конце недопустимы или опасны
+value = strings.Trim(value, ". ")
+if value == "" {
+return "untitled"
+}
+return value
+}
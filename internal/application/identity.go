@@ -0,0 +1,129 @@
+package application
+
+import (
+"context"
+"crypto/sha256"
+"encoding/hex"
+"fmt"
+"net/url"
+"rsshub/internal/domain"
+"strconv"
+"strings"
+)
+
+// trackingParams содержит параметры ссылок, которые не влияют на содержимое
+// страницы и часто меняются от загрузки к загрузке
+var trackingParams = map[string]bool{
+"fbclid":  true,
+"gclid":   true,
+"yclid":   true,
+"dclid":   true,
+"msclkid": true,
+"mc_cid":  true,
+"mc_eid":  true,
+"_ga":     true,
+"_hsenc":  true,
+"_hsmi":   true,
+"igshid":  true,
+}
+
+// articleGUID возвращает идентификатор статьи в пределах канала:
+// guid/Atom id элемента, иначе нормализованную ссылку, иначе хеш содержимого
+func articleGUID(item domain.FeedItem) string {
+if guid := strings.TrimSpace(item.GUID); guid != "" {
+return guid
+}
+
+if link := normalizeLink(item.Link); link != "" {
+return link
+}
+
+hash := sha256.Sum256([]byte(item.Title + "\n" + item.Description))
+return "sha256:" + hex.EncodeToString(hash[:])
+}
+
+// rekeyLegacyArticles заменяет идентификаторы статей, сохраненных до появления
+// guid, нормализованными ссылками. Иначе статья, ссылка которой изменилась
+// только параметрами отслеживания, не была бы найдена и продублировалась бы.
+func (a *RSSAggregator) rekeyLegacyArticles(ctx context.Context) {
+count, err := a.repo.(domain.ArticleRepository).RekeyLegacyArticles(ctx, normalizeLink)
+if err != nil {
+fmt.Printf("Ошибка обновления идентификаторов статей: %v\n", err)
+return
+}
+if count > 0 {
+fmt.Printf("Обновлены идентификаторы %d статей\n", count)
+}
+}
+
+// legacyArticleGUID возвращает идентификатор, который статья получала до
+// разрешения относительных ссылок, если он отличается от articleGUID
+func legacyArticleGUID(item domain.FeedItem) string {
+if strings.TrimSpace(item.GUID) != "" {
+return ""
+}
+legacy := normalizeLink(item.SourceLink)
+if legacy == "" || legacy == normalizeLink(item.Link) {
+return ""
+}
+return legacy
+}
+
+// contentHash вычисляет хеш содержимого элемента, по которому определяется,
+// что статья изменилась. Учитывается и <updated>, если канал его указывает.
+func contentHash(item domain.FeedItem) string {
+parts := []string{
+item.Title, normalizeLink(item.Link), item.Description, item.Content, item.PubDate, item.Updated,
+item.Image, strconv.Itoa(item.Episode), strconv.Itoa(item.Season),
+}
+for _, enclosure := range item.Enclosures {
+parts = append(parts, enclosure.URL, enclosure.MIMEType, strconv.FormatInt(enclosure.Length, 10))
+}
+// Отдельная метка не дает спутать списки авторов и категорий
+parts = append(parts, "authors:")
+parts = append(parts, item.Authors...)
+parts = append(parts, "categories:")
+parts = append(parts, item.Categories...)
+
+hash := sha256.New()
+for _, part := range parts {
+hash.Write([]byte(part))
+// Разделитель исключает совпадение хешей при переносе текста между полями
+hash.Write([]byte{0})
+}
+return hex.EncodeToString(hash.Sum(nil))
+}
+
+// normalizeLink приводит ссылку к каноническому виду: схема и хост в нижнем
+// регистре, без стандартного порта, фрагмента и параметров отслеживания
+func normalizeLink(link string) string {
+link = strings.TrimSpace(link)
+if link == "" {
+return ""
+}
+
+parsed, err := url.Parse(link)
+if err != nil || parsed.Host == "" {
+return link
+}
+
+parsed.Scheme = strings.ToLower(parsed.Scheme)
+parsed.Host = strings.ToLower(parsed.Host)
+if port := parsed.Port(); (parsed.Scheme == "http" && port == "80") || (parsed.Scheme == "https" && port == "443") {
+parsed.Host = strings.TrimSuffix(parsed.Host, ":"+port)
+}
+parsed.Fragment = ""
+parsed.RawFragment = ""
+
+query := parsed.Query()
+for name := range query {
+lower := strings.ToLower(name)
+if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+query.Del(name)
+}
+}
+// Encode сортирует параметры по имени, поэтому порядок параметров не важен
+parsed.RawQuery = query.Encode()
+
+return parsed.String()
+}
@@ -0,0 +1,191 @@
+package application
+
+import (
+"context"
+"fmt"
+"rsshub/internal/domain"
+"time"
+)
+
+// schedulerResolution задает максимальный период проверки каналов, время
+// обновления которых наступило. Без него канал с интервалом, равным периоду
+// тикера, пропускал бы каждый второй тик.
+const schedulerResolution = 30 * time.Second
+
+// claimLease задает, на сколько откладывается следующая загрузка канала,
+// переданного воркеру. Срок должен превышать время загрузки и ожидания
+// свободного воркера; если процесс завершится, не обработав канал,
+// канал будет загружен снова по истечении срока.
+const claimLease = 15 * time.Minute
+
+// publishingSample задает количество последних статей, по которым
+// оценивается частота публикаций канала
+const publishingSample = 10
+
+// fetchOutcome описывает результат загрузки канала для планировщика
+type fetchOutcome struct {
+// fetched равен true, если сервер ответил (в том числе 304 Not Modified)
+fetched  bool
+newItems int
+}
+
+// tickInterval возвращает период тикера для заданного интервала обновления
+func tickInterval(interval time.Duration) time.Duration {
+if interval < schedulerResolution {
+return interval
+}
+return schedulerResolution
+}
+
+// EnableAdaptiveScheduling включает адаптивное планирование для каналов
+// без собственного интервала. Интервал подстраивается под частоту
+// публикаций канала в пределах [minInterval, maxInterval].
+func (a *RSSAggregator) EnableAdaptiveScheduling(minInterval, maxInterval time.Duration) error {
+if minInterval <= 0 || maxInterval < minInterval {
+return fmt.Errorf("некорректные границы адаптивного интервала: %v - %v", minInterval, maxInterval)
+}
+
+a.mu.Lock()
+defer a.mu.Unlock()
+
+a.adaptive = true
+a.minInterval = minInterval
+a.maxInterval = maxInterval
+return nil
+}
+
+// refreshInterval возвращает интервал обновления канала: собственный,
+// если он задан, иначе адаптивный (если включен) или глобальный интервал агрегатора
+func (a *RSSAggregator) refreshInterval(feed *domain.Feed) time.Duration {
+if feed.RefreshInterval > 0 {
+return feed.RefreshInterval
+}
+
+a.mu.Lock()
+defer a.mu.Unlock()
+
+if a.adaptive && feed.AdaptiveInterval > 0 {
+return feed.AdaptiveInterval
+}
+return a.interval
+}
+
+// scheduleNext пересчитывает адаптивный интервал и планирует следующую загрузку канала
+func (a *RSSAggregator) scheduleNext(ctx context.Context, workerID int, feed *domain.Feed, outcome fetchOutcome) {
+if a.isAdaptive() && feed.RefreshInterval == 0 && outcome.fetched {
+interval, err := a.adaptInterval(ctx, feed, outcome)
+if err != nil {
+fmt.Printf("Воркер %d: ошибка вычисления адаптивного интервала канала %s: %v\n",
+workerID, feed.Name, err)
+} else {
+feed.AdaptiveInterval = interval
+}
+}
+
+delay := a.refreshInterval(feed)
+if !feed.IgnoreHints {
+delay = applyPollingHints(delay, feed.Hints, time.Now())
+}
+
+if err := a.repo.ScheduleFeed(ctx, feed.ID, delay); err != nil {
+fmt.Printf("Воркер %d: ошибка планирования канала %s: %v\n",
+workerID, feed.Name, err)
+}
+}
+
+// isAdaptive сообщает, включено ли адаптивное планирование
+func (a *RSSAggregator) isAdaptive() bool {
+a.mu.Lock()
+defer a.mu.Unlock()
+return a.adaptive
+}
+
+// adaptInterval вычисляет и сохраняет новый адаптивный интервал канала.
+//
+// Интервал уменьшается вдвое, если загрузка принесла новые статьи, и
+// увеличивается в полтора раза, если нет. Затем он усредняется с половиной
+// среднего промежутка между последними публикациями, чтобы одна случайная
+// загрузка не сбивала оценку, и ограничивается границами агрегатора.
+func (a *RSSAggregator) adaptInterval(ctx context.Context, feed *domain.Feed, outcome fetchOutcome) (time.Duration, error) {
+a.mu.Lock()
+current := feed.AdaptiveInterval
+if current <= 0 {
+current = a.interval
+}
+minInterval, maxInterval := a.minInterval, a.maxInterval
+a.mu.Unlock()
+
+next := current * 3 / 2
+if outcome.newItems > 0 {
+next = current / 2
+}
+
+gap, err := a.repo.(domain.ArticleRepository).GetPublishingInterval(ctx, feed.ID, publishingSample)
+if err != nil {
+return 0, err
+}
+if gap > 0 {
+next = (next + gap/2) / 2
+}
+
+next = clampDuration(next, minInterval, maxInterval)
+
+if next != feed.AdaptiveInterval {
+if err := a.repo.UpdateAdaptiveInterval(ctx, feed.ID, next); err != nil {
+return 0, err
+}
+}
+
+return next, nil
+}
+
+// applyPollingHints корректирует задержку до следующей загрузки с учетом
+// рекомендаций издателя: задержка не меньше ttl, периода sy:updatePeriod и
+// времени жизни HTTP-кеша, а момент загрузки не попадает в skipHours/skipDays.
+func applyPollingHints(delay time.Duration, hints domain.PollingHints, now time.Time) time.Duration {
+for _, floor := range []time.Duration{hints.TTL, hints.UpdatePeriod, hints.CacheMaxAge} {
+if floor > delay {
+delay = floor
+}
+}
+
+if len(hints.SkipHours) == 0 && len(hints.SkipDays) == 0 {
+return delay
+}
+
+skipHours := make(map[int]bool)
+for _, hour := range hints.SkipHours {
+skipHours[hour] = true
+}
+skipDays := make(map[time.Weekday]bool)
+for _, day := range hints.SkipDays {
+skipDays[day] = true
+}
+
+// skipHours и skipDays задаются в GMT. Ограничиваем перебор неделей,
+// чтобы канал, запрещающий все часы, не зациклил планировщик.
+next := now.Add(delay).UTC()
+for i := 0; i < 7*24; i++ {
+switch {
+case skipDays[next.Weekday()]:
+next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, time.UTC)
+case skipHours[next.Hour()]:
+next = next.Truncate(time.Hour).Add(time.Hour)
+default:
+return next.Sub(now)
+}
+}
+
+return delay
+}
+
+// clampDuration ограничивает значение заданными границами
+func clampDuration(d, minValue, maxValue time.Duration) time.Duration {
+if d < minValue {
+return minValue
+}
+if d > maxValue {
+return maxValue
+}
+return d
+}
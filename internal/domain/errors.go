@@ -0,0 +1,27 @@
+package domain
+
+import (
+"errors"
+"fmt"
+)
+
+// ErrNotModified возвращается парсером, если сервер ответил 304 Not Modified
+var ErrNotModified = errors.New("канал не изменился")
+
+// ErrDownloadTooLarge возвращается при загрузке вложения, размер которого превышает допустимый
+var ErrDownloadTooLarge = errors.New("размер файла превышает допустимый")
+
+// FeedLimitError возвращается парсером, если документ канала превышает одно
+// из ограничений: размер ответа, размер распакованного ответа, количество
+// элементов или глубину вложенности. Такой документ не разбирается.
+type FeedLimitError struct {
+// Limit описывает нарушенное ограничение
+Limit string
+// Max — значение ограничения
+Max int64
+}
+
+// Error возвращает описание ошибки
+func (e *FeedLimitError) Error() string {
+return fmt.Sprintf("документ канала отклонен: %s превышает %d", e.Limit, e.Max)
+}
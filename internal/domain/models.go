@@ -6,11 +6,73 @@ import (
 
 // Feed представляет RSS-канал
 type Feed struct {
-ID        int       `db:"id"`
-CreatedAt time.Time `db:"created_at"`
-UpdatedAt time.Time `db:"updated_at"`
-Name      string    `db:"name"`
-URL       string    `db:"url"`
+ID           int       `db:"id"`
+CreatedAt    time.Time `db:"created_at"`
+UpdatedAt    time.Time `db:"updated_at"`
+Name         string    `db:"name"`
+URL          string    `db:"url"`
+Category     string    `db:"category"`
+ETag         string    `db:"etag"`
+LastModified string    `db:"last_modified"`
+// RefreshInterval задает собственный интервал обновления канала;
+// нулевое значение означает глобальный интервал агрегатора
+RefreshInterval time.Duration `db:"refresh_interval"`
+NextFetchAt     time.Time     `db:"next_fetch_at"`
+// AdaptiveInterval — интервал, вычисленный по частоте публикаций канала
+AdaptiveInterval time.Duration `db:"adaptive_interval"`
+// Hints — последние рекомендации издателя о частоте опроса;
+// IgnoreHints позволяет опрашивать канал чаще, чем они разрешают
+Hints       PollingHints `db:"poll_hints"`
+IgnoreHints bool         `db:"ignore_hints"`
+// DownloadEnclosures включает загрузку вложений канала на диск
+DownloadEnclosures bool `db:"download_enclosures"`
+// DateFallback определяет дату статьи, если дата в канале отсутствует или не распознана
+DateFallback DateFallback `db:"date_fallback"`
+// Metadata содержит сведения о канале из последнего загруженного документа
+Metadata FeedMetadata
+// ParseWarning описывает ошибки в последнем загруженном документе,
+// которые удалось обойти при разборе; пусто, если документ корректен
+ParseWarning string `db:"parse_warning"`
+}
+
+// DateFallback определяет, как поступать со статьей без распознанной даты
+type DateFallback string
+
+const (
+// DateFallbackNow — использовать время загрузки статьи
+DateFallbackNow DateFallback = "now"
+// DateFallbackOldest — считать статью самой старой, чтобы она не поднималась в начало списка
+DateFallbackOldest DateFallback = "oldest"
+// DateFallbackSkip — не сохранять статью
+DateFallbackSkip DateFallback = "skip"
+)
+
+// DateFailure описывает элемент канала, дату которого не удалось распознать
+type DateFailure struct {
+FeedID      int       `db:"feed_id"`
+GUID        string    `db:"guid"`
+Title       string    `db:"title"`
+Value       string    `db:"value"`
+FirstSeenAt time.Time `db:"first_seen_at"`
+LastSeenAt  time.Time `db:"last_seen_at"`
+FeedName    string
+}
+
+// FeedMetadata содержит сведения, которые канал сообщает о себе
+type FeedMetadata struct {
+Title       string `db:"title"`
+Description string `db:"description"`
+SiteURL     string `db:"site_url"`
+ImageURL    string `db:"image_url"`
+IconURL     string `db:"icon_url"`
+Language    string `db:"language"`
+Generator   string `db:"generator"`
+}
+
+// CacheValidators содержит валидаторы HTTP-кеша для условного GET
+type CacheValidators struct {
+ETag         string
+LastModified string
 }
 
 // Article представляет статью из RSS-канала
@@ -23,24 +85,177 @@ Link        string    `db:"link"`
 PublishedAt time.Time `db:"published_at"`
 Description string    `db:"description"`
 FeedID      int       `db:"feed_id"`
+// GUID идентифицирует статью в пределах канала: guid/Atom id,
+// нормализованная ссылка или хеш содержимого
+GUID string `db:"guid"`
+// LinkGUID содержит идентификатор, который статья получила бы без guid
+// (нормализованную ссылку). Статья с таким идентификатором, сохраненная
+// до появления guid у элемента, получает GUID.
+LinkGUID string
+// LegacyGUID содержит прежний идентификатор статьи, если он отличается от GUID:
+// до разрешения относительных ссылок статья без guid идентифицировалась
+// относительной ссылкой. Статья с прежним идентификатором получает новый.
+LegacyGUID string
+// ContentHash позволяет обнаружить изменение статьи в канале
+ContentHash string `db:"content_hash"`
+// Content содержит полный текст статьи (content:encoded, Atom content)
+Content string `db:"content"`
+// DescriptionText и ContentText — текстовые версии описания и полного
+// текста без разметки для вывода в терминал
+DescriptionText string `db:"description_text"`
+ContentText     string `db:"content_text"`
+// ImageURL, Episode и Season заполняются для эпизодов подкастов и видео
+ImageURL   string `db:"image_url"`
+Episode    int    `db:"episode"`
+Season     int    `db:"season"`
+Enclosures []Enclosure
+Authors    []string
+Categories []string
+}
+
+// ArticleChange описывает результат сохранения статьи
+type ArticleChange int
+
+const (
+// ArticleUnchanged — статья уже сохранена и не изменилась
+ArticleUnchanged ArticleChange = iota
+// ArticleInserted — добавлена новая статья
+ArticleInserted
+// ArticleUpdated — статья изменилась, прежняя версия сохранена в истории
+ArticleUpdated
+)
+
+// ArticleFilter задает условия выборки статей. Пустые поля не ограничивают выборку.
+type ArticleFilter struct {
+FeedName string
+Author   string
+Category string
+Limit    int
+}
+
+// ArticleRevision представляет прежнюю версию статьи
+type ArticleRevision struct {
+ID          int       `db:"id"`
+ArticleID   int       `db:"article_id"`
+CreatedAt   time.Time `db:"created_at"`
+Title       string    `db:"title"`
+Link        string    `db:"link"`
+PublishedAt time.Time `db:"published_at"`
+Description string    `db:"description"`
+ContentHash string    `db:"content_hash"`
+Content     string    `db:"content"`
+}
+
+// ParsedFeed представляет канал, разобранный из любого поддерживаемого формата
+type ParsedFeed struct {
+Title       string
+Link        string
+Description string
+// ImageURL — логотип канала (<image>, itunes:image, Atom logo, JSON Feed icon);
+// IconURL — значок сайта (Atom icon, JSON Feed favicon)
+ImageURL   string
+IconURL    string
+Language   string
+Generator  string
+Items      []FeedItem
+Validators CacheValidators
+Hints      PollingHints
+// Warning описывает ошибки в документе, которые удалось обойти при разборе
+Warning string
+}
+
+// PollingHints содержит рекомендации издателя о частоте опроса канала
+// (RSS ttl, skipHours, skipDays, sy:updatePeriod, Cache-Control/Expires)
+type PollingHints struct {
+TTL          time.Duration  `json:"ttl,omitempty"`
+UpdatePeriod time.Duration  `json:"update_period,omitempty"`
+CacheMaxAge  time.Duration  `json:"cache_max_age,omitempty"`
+SkipHours    []int          `json:"skip_hours,omitempty"`
+SkipDays     []time.Weekday `json:"skip_days,omitempty"`
+}
+
+// FeedItem представляет элемент канала независимо от исходного формата
+type FeedItem struct {
+GUID  string
+Title string
+Link  string
+// SourceLink содержит ссылку в том виде, в котором она указана в документе,
+// до разрешения относительного адреса
+SourceLink  string
+Description string
+// Content содержит полный текст элемента, если канал его публикует
+Content string
+// Description и Content содержат очищенный HTML, DescriptionText
+// и ContentText — их текстовые версии
+DescriptionText string
+ContentText     string
+PubDate         string
+Updated         string
+Authors         []string
+Categories      []string
+Enclosures      []Enclosure
+// Image содержит обложку элемента (itunes:image, media:thumbnail)
+Image string
+// Episode и Season содержат номера эпизода и сезона подкаста, 0 если не указаны
+Episode int
+Season  int
+}
+
+// DownloadStatus описывает состояние загрузки вложения
+type DownloadStatus string
+
+const (
+// DownloadPending — загрузка ожидает очереди
+DownloadPending DownloadStatus = "pending"
+// DownloadActive — файл загружается
+DownloadActive DownloadStatus = "downloading"
+// DownloadDone — файл загружен
+DownloadDone DownloadStatus = "done"
+// DownloadFailed — загрузка завершилась ошибкой и будет повторена
+DownloadFailed DownloadStatus = "failed"
+// DownloadSkipped — файл не загружается (например, превышен допустимый размер)
+DownloadSkipped DownloadStatus = "skipped"
+)
+
+// Download представляет загрузку вложения статьи на диск
+type Download struct {
+ID          int            `db:"id"`
+EnclosureID int            `db:"enclosure_id"`
+CreatedAt   time.Time      `db:"created_at"`
+UpdatedAt   time.Time      `db:"updated_at"`
+Status      DownloadStatus `db:"status"`
+Path        string         `db:"path"`
+// Downloaded и Size содержат загруженный и полный размер файла в байтах;
+// Size равен 0, если сервер не сообщил размер
+Downloaded int64  `db:"downloaded"`
+Size       int64  `db:"size"`
+Attempts   int    `db:"attempts"`
+Error      string `db:"error"`
+
+// Сведения о вложении, статье и канале для именования файла и вывода
+URL          string
+MIMEType     string
+FeedName     string
+ArticleTitle string
+PublishedAt  time.Time
+Episode      int
 }
 
-// RSS представляет структуру RSS-канала
-type RSS struct {
-Channel struct {
-Title       string    `xml:"title"`
-Link        string    `xml:"link"`
-Description string    `xml:"description"`
-Items       []RSSItem `xml:"item"`
-} `xml:"channel"`
+// Enclosure представляет вложение элемента канала (аудио, видео, изображение)
+type Enclosure struct {
+ID        int           `db:"id"`
+ArticleID int           `db:"article_id"`
+URL       string        `db:"url"`
+MIMEType  string        `db:"mime_type"`
+Length    int64         `db:"length"`
+Duration  time.Duration `db:"duration"`
 }
 
-// RSSItem представляет элемент RSS-канала
-type RSSItem struct {
-Title       string `xml:"title"`
-Link        string `xml:"link"`
-Description string `xml:"description"`
-PubDate     string `xml:"pubDate"`
+// DiscoveredFeed представляет канал, найденный на HTML-странице
+type DiscoveredFeed struct {
+URL   string
+Title string
+Type  string
 }
 
 // AggregatorState представляет состояние агрегатора
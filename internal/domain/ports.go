@@ -9,24 +9,68 @@ import (
 // FeedRepository определяет интерфейс для работы с хранилищем каналов
 type FeedRepository interface {
 AddFeed(ctx context.Context, feed *Feed) error
+GetFeedByID(ctx context.Context, id int) (*Feed, error)
 GetFeedByName(ctx context.Context, name string) (*Feed, error)
+GetFeedByURL(ctx context.Context, url string) (*Feed, error)
 ListFeeds(ctx context.Context, limit int) ([]*Feed, error)
+ListAllFeeds(ctx context.Context) ([]*Feed, error)
 DeleteFeed(ctx context.Context, name string) error
-GetOutdatedFeeds(ctx context.Context, count int) ([]*Feed, error)
+ClaimOutdatedFeeds(ctx context.Context, count int, lease time.Duration) ([]*Feed, error)
+UpdateFeed(ctx context.Context, feed *Feed) error
 UpdateFeedTimestamp(ctx context.Context, feedID int) error
+ScheduleFeed(ctx context.Context, feedID int, delay time.Duration) error
+UpdateAdaptiveInterval(ctx context.Context, feedID int, interval time.Duration) error
+UpdatePollingHints(ctx context.Context, feedID int, hints PollingHints) error
+UpdateFeedCacheValidators(ctx context.Context, feedID int, validators CacheValidators) error
+UpdateFeedMetadata(ctx context.Context, feedID int, metadata FeedMetadata) error
+UpdateFeedParseWarning(ctx context.Context, feedID int, warning string) error
 Close() error
 DB() *sql.DB
 }
 
 // ArticleRepository определяет интерфейс для работы с хранилищем статей
 type ArticleRepository interface {
-AddArticle(ctx context.Context, article *Article) error
-GetArticlesByFeed(ctx context.Context, feedName string, limit int) ([]*Article, error)
+SaveArticle(ctx context.Context, article *Article) (ArticleChange, error)
+// RekeyLegacyArticles заменяет идентификатор статей, сохраненных до появления
+// guid, на key(ссылка статьи) и возвращает количество обновленных статей
+RekeyLegacyArticles(ctx context.Context, key func(link string) string) (int, error)
+GetArticleByID(ctx context.Context, id int) (*Article, error)
+GetArticleRevisions(ctx context.Context, articleID int) ([]*ArticleRevision, error)
+GetPublishingInterval(ctx context.Context, feedID int, sample int) (time.Duration, error)
+GetArticles(ctx context.Context, filter ArticleFilter) ([]*Article, error)
+RecordDateFailure(ctx context.Context, failure *DateFailure) error
+ListDateFailures(ctx context.Context, feedName string, limit int) ([]*DateFailure, error)
 }
 
-// RSSParser определяет интерфейс для парсинга RSS
+// DownloadRepository определяет интерфейс для работы с очередью загрузок вложений
+type DownloadRepository interface {
+// QueueDownloads добавляет в очередь вложения каналов с включенной загрузкой
+QueueDownloads(ctx context.Context) (int, error)
+// ClaimDownloads отмечает до limit ожидающих загрузок как выполняемые и возвращает их
+ClaimDownloads(ctx context.Context, limit int, maxAttempts int) ([]*Download, error)
+// ResetActiveDownloads возвращает в очередь загрузки, прерванные остановкой процесса
+ResetActiveDownloads(ctx context.Context) error
+UpdateDownload(ctx context.Context, download *Download) error
+ListDownloads(ctx context.Context, feedName string, status DownloadStatus, limit int) ([]*Download, error)
+}
+
+// EnclosureFetcher определяет интерфейс для загрузки файлов вложений.
+// Загрузка продолжается с конца ранее загруженной части файла; progress
+// вызывается с количеством загруженных байт и полным размером (0, если неизвестен).
+// Если файл больше maxSize, возвращается ErrDownloadTooLarge.
+type EnclosureFetcher interface {
+Fetch(ctx context.Context, url, path string, maxSize int64, progress func(downloaded, size int64)) error
+}
+
+// RSSParser определяет интерфейс для парсинга каналов (RSS, RDF, Atom, JSON Feed).
+// Если валидаторы переданы и канал не изменился, возвращается ErrNotModified.
 type RSSParser interface {
-ParseFeed(url string) (*RSS, error)
+ParseFeed(ctx context.Context, url string, validators CacheValidators) (*ParsedFeed, error)
+}
+
+// FeedDiscoverer определяет интерфейс для поиска каналов по адресу страницы
+type FeedDiscoverer interface {
+DiscoverFeeds(ctx context.Context, pageURL string) ([]DiscoveredFeed, error)
 }
 
 // Aggregator определяет интерфейс для работы с агрегатором
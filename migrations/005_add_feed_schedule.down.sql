@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS feeds_next_fetch_at_idx;
+
+ALTER TABLE feeds
+    DROP COLUMN IF EXISTS refresh_interval,
+    DROP COLUMN IF EXISTS next_fetch_at;
@@ -0,0 +1,6 @@
+-- refresh_interval хранится в секундах, 0 означает глобальный интервал агрегатора
+ALTER TABLE feeds
+    ADD COLUMN IF NOT EXISTS refresh_interval BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS next_fetch_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS feeds_next_fetch_at_idx ON feeds (next_fetch_at);
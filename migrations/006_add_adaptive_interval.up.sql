@@ -0,0 +1,2 @@
+-- adaptive_interval хранится в секундах, 0 означает, что интервал еще не вычислен
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS adaptive_interval BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+ALTER TABLE feeds
+    DROP COLUMN IF EXISTS poll_hints,
+    DROP COLUMN IF EXISTS ignore_hints;
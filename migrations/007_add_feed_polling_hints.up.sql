@@ -0,0 +1,3 @@
+ALTER TABLE feeds
+    ADD COLUMN IF NOT EXISTS poll_hints TEXT NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS ignore_hints BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS articles_feed_link_idx;
+ALTER TABLE articles DROP CONSTRAINT IF EXISTS articles_feed_guid_key;
+ALTER TABLE articles DROP COLUMN IF EXISTS guid;
+
+-- Перед восстановлением уникальности ссылок удаляем повторы
+DELETE FROM articles a USING articles b WHERE a.link = b.link AND a.id > b.id;
+ALTER TABLE articles ADD CONSTRAINT articles_link_key UNIQUE (link);
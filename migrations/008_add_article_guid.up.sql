@@ -0,0 +1,15 @@
+-- Статьи идентифицируются в пределах канала по guid/Atom id,
+-- а не глобально по ссылке
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS guid TEXT;
+
+-- Существующие статьи дедуплицировались по ссылке, поэтому ссылка становится
+-- их идентификатором. Миграция 019 заменяет его нормализованной ссылкой,
+-- а при следующей загрузке канала такие статьи получают настоящий guid
+-- (см. RekeyLegacyArticles и SaveArticle).
+UPDATE articles SET guid = link WHERE guid IS NULL;
+
+ALTER TABLE articles ALTER COLUMN guid SET NOT NULL;
+ALTER TABLE articles DROP CONSTRAINT IF EXISTS articles_link_key;
+ALTER TABLE articles ADD CONSTRAINT articles_feed_guid_key UNIQUE (feed_id, guid);
+
+CREATE INDEX IF NOT EXISTS articles_feed_link_idx ON articles (feed_id, link);
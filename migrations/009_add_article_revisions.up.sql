@@ -0,0 +1,15 @@
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS content_hash TEXT NOT NULL DEFAULT '';
+
+-- История изменений статей: каждая строка хранит версию статьи до изменения
+CREATE TABLE IF NOT EXISTS article_revisions (
+    id SERIAL PRIMARY KEY,
+    article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    title TEXT NOT NULL,
+    link TEXT NOT NULL,
+    published_at TIMESTAMP NOT NULL,
+    description TEXT,
+    content_hash TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS article_revisions_article_id_idx ON article_revisions (article_id);
@@ -0,0 +1,2 @@
+ALTER TABLE article_revisions DROP COLUMN IF EXISTS content;
+ALTER TABLE articles DROP COLUMN IF EXISTS content;
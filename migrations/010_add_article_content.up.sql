@@ -0,0 +1,6 @@
+-- Полный текст статьи (content:encoded, Atom content, JSON Feed content_html)
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS content TEXT NOT NULL DEFAULT '';
+ALTER TABLE article_revisions ADD COLUMN IF NOT EXISTS content TEXT NOT NULL DEFAULT '';
+
+-- Полный текст входит в content_hash (см. SaveArticle)
+UPDATE articles SET content_hash = '';
@@ -0,0 +1,4 @@
+ALTER TABLE articles DROP COLUMN IF EXISTS season;
+ALTER TABLE articles DROP COLUMN IF EXISTS episode;
+ALTER TABLE articles DROP COLUMN IF EXISTS image_url;
+DROP TABLE IF EXISTS enclosures;
@@ -0,0 +1,19 @@
+-- Вложения статей: аудио и видео подкастов (enclosure, media:content, Atom rel="enclosure")
+CREATE TABLE IF NOT EXISTS enclosures (
+    id SERIAL PRIMARY KEY,
+    article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    mime_type TEXT NOT NULL DEFAULT '',
+    length BIGINT NOT NULL DEFAULT 0,
+    -- Длительность в секундах
+    duration BIGINT NOT NULL DEFAULT 0,
+    CONSTRAINT enclosures_article_url_key UNIQUE (article_id, url)
+);
+
+-- Обложка и номера эпизода подкаста (itunes:image, media:thumbnail, itunes:episode)
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS image_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS episode INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS season INTEGER NOT NULL DEFAULT 0;
+
+-- Вложения, обложка и номера эпизода входят в content_hash (см. SaveArticle)
+UPDATE articles SET content_hash = '';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS downloads;
+ALTER TABLE feeds DROP COLUMN IF EXISTS download_enclosures;
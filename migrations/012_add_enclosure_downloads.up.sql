@@ -0,0 +1,19 @@
+-- Загрузка вложений включается отдельно для каждого канала
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS download_enclosures BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Очередь и состояние загрузок вложений
+CREATE TABLE IF NOT EXISTS downloads (
+    id SERIAL PRIMARY KEY,
+    enclosure_id INTEGER NOT NULL REFERENCES enclosures(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    status TEXT NOT NULL DEFAULT 'pending',
+    path TEXT NOT NULL DEFAULT '',
+    downloaded BIGINT NOT NULL DEFAULT 0,
+    size BIGINT NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    CONSTRAINT downloads_enclosure_key UNIQUE (enclosure_id)
+);
+
+CREATE INDEX IF NOT EXISTS downloads_status_idx ON downloads (status, updated_at);
@@ -0,0 +1,32 @@
+-- Авторы и категории статей хранятся в справочниках без учета регистра
+CREATE TABLE IF NOT EXISTS authors (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS authors_name_key ON authors (LOWER(name));
+
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS categories_name_key ON categories (LOWER(name));
+
+-- Связи статей с авторами и категориями; position сохраняет порядок из канала
+CREATE TABLE IF NOT EXISTS article_authors (
+    article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    author_id INTEGER NOT NULL REFERENCES authors(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (article_id, author_id)
+);
+CREATE INDEX IF NOT EXISTS article_authors_author_id_idx ON article_authors (author_id);
+
+CREATE TABLE IF NOT EXISTS article_categories (
+    article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (article_id, category_id)
+);
+CREATE INDEX IF NOT EXISTS article_categories_category_id_idx ON article_categories (category_id);
+
+-- Авторы и категории входят в content_hash (см. SaveArticle)
+UPDATE articles SET content_hash = '';
@@ -0,0 +1,7 @@
+ALTER TABLE feeds DROP COLUMN IF EXISTS generator;
+ALTER TABLE feeds DROP COLUMN IF EXISTS language;
+ALTER TABLE feeds DROP COLUMN IF EXISTS icon_url;
+ALTER TABLE feeds DROP COLUMN IF EXISTS image_url;
+ALTER TABLE feeds DROP COLUMN IF EXISTS site_url;
+ALTER TABLE feeds DROP COLUMN IF EXISTS description;
+ALTER TABLE feeds DROP COLUMN IF EXISTS title;
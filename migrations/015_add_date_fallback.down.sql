@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS date_failures;
+ALTER TABLE feeds DROP COLUMN IF EXISTS date_fallback;
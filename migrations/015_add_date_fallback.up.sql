@@ -0,0 +1,13 @@
+-- Дата статьи, если дата в канале отсутствует или не распознана: now, oldest или skip
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS date_fallback TEXT NOT NULL DEFAULT 'now';
+
+-- Элементы каналов с нераспознанными датами
+CREATE TABLE IF NOT EXISTS date_failures (
+    feed_id INTEGER NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    guid TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    value TEXT NOT NULL,
+    first_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (feed_id, guid)
+);
@@ -0,0 +1,7 @@
+-- Текстовые версии описания и полного текста статьи без разметки
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS description_text TEXT NOT NULL DEFAULT '';
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS content_text TEXT NOT NULL DEFAULT '';
+
+-- Очистка разметки меняет description и content, от которых зависит
+-- content_hash; текстовые версии заполняются при следующей загрузке (см. SaveArticle)
+UPDATE articles SET content_hash = '';
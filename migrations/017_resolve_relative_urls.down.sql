@@ -0,0 +1,2 @@
+-- Миграция изменяет только данные, схема не меняется
+SELECT 1;
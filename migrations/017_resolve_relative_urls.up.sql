@@ -0,0 +1,5 @@
+-- Разрешение относительных адресов меняет ссылки статей и вложений и ссылки
+-- в тексте, от которых зависит content_hash (см. SaveArticle). Статьи без guid,
+-- идентифицированные относительной ссылкой, получают новый идентификатор
+-- при сохранении (LegacyGUID).
+UPDATE articles SET content_hash = '';
@@ -0,0 +1,2 @@
+-- Ошибки в последнем загруженном документе канала, которые удалось обойти при разборе
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS parse_warning TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+-- Статьи, идентификатор которых совпадает со ссылкой, сохранены до появления
+-- guid (см. 008_add_article_guid) или не имеют guid. При запуске агрегатора
+-- их идентификатор заменяется нормализованной ссылкой — тем же идентификатором,
+-- что получает элемент канала без guid (см. RekeyLegacyArticles).
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS legacy_guid BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE articles SET legacy_guid = TRUE WHERE guid = link;